@@ -0,0 +1,192 @@
+package algorithm
+
+import (
+	"backend/search"
+	"container/heap"
+	"iter"
+	"slices"
+)
+
+// A partially built recipe tree: every state fixes the recipe of one more element.
+// Open leaves are expanded leftmost first, so the chosen recipes form a preorder walk
+// of the finished tree and only the last choice needs to be stored per state
+type partialTree struct {
+	parent *partialTree
+	recipe []*search.ElementNode // Recipe chosen for the leaf expanded to reach this state
+	open   []openLeaf            // Leaves that still need a recipe, leftmost first
+	cost   int                   // Combinations chosen so far
+	bound  int                   // cost plus the smallest tree of every open leaf
+	need   []*search.ElementNode // Included elements not in the tree yet
+}
+
+type openLeaf struct {
+	element *search.ElementNode
+	depth   int
+}
+
+type partialTreeQueue []*partialTree
+
+func (q partialTreeQueue) Len() int { return len(q) }
+func (q partialTreeQueue) Less(i, j int) bool {
+	if q[i].bound != q[j].bound {
+		return q[i].bound < q[j].bound
+	}
+	// Among equally promising states, finish the one furthest along before starting another.
+	// The bound is exact for the smallest trees, so this walks straight down one of them
+	if q[i].cost != q[j].cost {
+		return q[i].cost > q[j].cost
+	}
+	return len(q[i].open) < len(q[j].open)
+}
+func (q partialTreeQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *partialTreeQueue) Push(x any)   { *q = append(*q, x.(*partialTree)) }
+func (q *partialTreeQueue) Pop() any {
+	old := *q
+	last := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return last
+}
+
+// Partial trees A* may expand, or hold in its queue, before it gives up. Trees of equal size
+// can number in the millions on the scraped dataset, each of them a state in the queue
+const maxPartialTrees = 200000
+
+// Combinations in the smallest usable tree of every element, as search.MinimalRecipes but
+// without the excluded elements. An open leaf needs at least this many more, and for the
+// first tree exactly this many, so A* walks straight down to it
+func recipeSizes(graph *search.RecipeGraph, constraints Constraints) map[*search.ElementNode]int {
+	return search.TierFold[int]{
+		Skip: func(element *search.ElementNode) bool { return slices.Contains(constraints.Exclude, element) },
+		Base: func(*search.ElementNode) int { return 0 },
+		Recipe: func(_ *search.ElementNode, _ []*search.ElementNode, left, right int) int {
+			return 1 + left + right
+		},
+		Better: func(size, current int) bool { return size < current },
+	}.Run(graph)
+}
+
+// AStar yields the recipe trees of target in order of increasing size, expanding the
+// partial tree with the lowest cost plus smallest tree bound first. It stops with
+// stats.LimitHit set once it expanded or queued maxPartialTrees partial trees
+func AStar(req SearchRequest, stats *SearchStats) iter.Seq[*RecipeTree] {
+	sizes := recipeSizes(req.Graph, req.Constraints)
+	include := newInclusion(req.Graph, req.Constraints)
+	isBase := func(element *search.ElementNode) bool {
+		return slices.Contains(req.Graph.BaseElements, element)
+	}
+
+	return func(yield func(*RecipeTree) bool) {
+		if _, craftable := sizes[req.Target]; !craftable || !include.possible(req.Constraints.Include, req.Target) {
+			return
+		}
+		if isBase(req.Target) {
+			yield(&RecipeTree{ID: req.Target.ID, Element: req.Target.Name})
+			return
+		}
+
+		queue := &partialTreeQueue{{
+			open:  []openLeaf{{element: req.Target}},
+			bound: sizes[req.Target],
+			need:  req.Constraints.Include,
+		}}
+		expanded := 0
+		for queue.Len() > 0 {
+			stats.observeFrontier(queue.Len())
+			if expanded >= maxPartialTrees || queue.Len() >= maxPartialTrees {
+				stats.LimitHit = true
+				return
+			}
+			state := heap.Pop(queue).(*partialTree)
+			if len(state.open) == 0 {
+				if !yield(state.build(req.Target, isBase)) {
+					return
+				}
+				continue
+			}
+
+			leaf, rest := state.open[0], state.open[1:]
+			element := leaf.element
+			expanded++
+			stats.NodesExpanded++
+			notify(req.Observer, SearchEvent{Type: EventNodeExpanded, Element: element.Name, Depth: leaf.depth})
+
+			for _, recipe := range element.Recipes {
+				stats.RecipesConsidered++
+				pruned := SearchEvent{Type: EventRecipePruned, Element: element.Name, Ingredients: recipeNames(recipe), Depth: leaf.depth}
+				if !search.UsableRecipe(element, recipe) {
+					stats.RecipesPrunedByTier++
+					pruned.Reason = PruneTier
+					notify(req.Observer, pruned)
+					continue
+				}
+				if !req.Constraints.allowsRecipe(recipe) {
+					pruned.Reason = PruneExcluded
+					notify(req.Observer, pruned)
+					continue
+				}
+				left, leftOk := sizes[recipe[0]]
+				right, rightOk := sizes[recipe[1]]
+				if !leftOk || !rightOk {
+					pruned.Reason = PruneUncraftable
+					notify(req.Observer, pruned)
+					continue
+				}
+
+				open := make([]openLeaf, 0, len(rest)+2)
+				for _, ingredient := range recipe {
+					if !isBase(ingredient) {
+						open = append(open, openLeaf{element: ingredient, depth: leaf.depth + 1})
+					}
+				}
+				open = append(open, rest...)
+				// Base ingredients are in the tree for good, what is still needed has to come from an open leaf
+				need := without(state.need, element, recipe[0], recipe[1])
+				if len(need) > 0 && !include.possible(need, leafElements(open)...) {
+					pruned.Reason = PruneIncluded
+					notify(req.Observer, pruned)
+					continue
+				}
+				heap.Push(queue, &partialTree{
+					parent: state,
+					recipe: recipe,
+					open:   open,
+					cost:   state.cost + 1,
+					bound:  state.bound - sizes[element] + 1 + left + right,
+					need:   need,
+				})
+			}
+		}
+	}
+}
+
+// The elements of the open leaves
+func leafElements(open []openLeaf) []*search.ElementNode {
+	elements := make([]*search.ElementNode, len(open))
+	for i, leaf := range open {
+		elements[i] = leaf.element
+	}
+	return elements
+}
+
+// Replays the chosen recipes in preorder to rebuild the finished tree
+func (state *partialTree) build(target *search.ElementNode, isBase func(*search.ElementNode) bool) *RecipeTree {
+	choices := make([][]*search.ElementNode, 0, state.cost)
+	for current := state; current.parent != nil; current = current.parent {
+		choices = append(choices, current.recipe)
+	}
+	slices.Reverse(choices)
+
+	next := 0
+	var grow func(element *search.ElementNode) *RecipeTree
+	grow = func(element *search.ElementNode) *RecipeTree {
+		node := &RecipeTree{ID: element.ID, Element: element.Name}
+		if isBase(element) {
+			return node
+		}
+		recipe := choices[next]
+		next++
+		node.Ingredients = []*RecipeTree{grow(recipe[0]), grow(recipe[1])}
+		return node
+	}
+	return grow(target)
+}
@@ -0,0 +1,115 @@
+package algorithm
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// A* hands out trees smallest first, starting with one as small as the exhaustive minimum.
+// Run with -v to compare the nodes it expands with ReverseBFS
+func TestAStarTreesAreSmallestFirst(t *testing.T) {
+	for name, graph := range testGraphs(t) {
+		minimal := newMinimalTrees(graph)
+		astarNodes, bfsNodes := 0, 0
+
+		for _, element := range graph.Elements[1:] {
+			result, err := Run("astar", SearchRequest{Target: element, Graph: graph, MaxPaths: 10})
+			if err != nil {
+				t.Fatalf("%s/%s: %v", name, element.Name, err)
+			}
+			if minimal.of(element) == nil {
+				if len(result.Trees) != 0 {
+					t.Errorf("%s/%s: uncraftable element has tree %s", name, element.Name, result.Trees[0])
+				}
+				continue
+			}
+			if len(result.Trees) == 0 {
+				t.Errorf("%s/%s: no tree found", name, element.Name)
+				continue
+			}
+			if size := result.Trees[0].Size(); size != minimal.size(element) {
+				t.Errorf("%s/%s: first tree has %d combinations, smallest has %d", name, element.Name, size, minimal.size(element))
+			}
+			for i := 1; i < len(result.Trees); i++ {
+				if result.Trees[i].Size() < result.Trees[i-1].Size() {
+					t.Errorf("%s/%s: tree %d is smaller than the one before it", name, element.Name, i)
+				}
+			}
+
+			bfs, err := Run("bfs", SearchRequest{Target: element, Graph: graph, MaxPaths: 1})
+			if err != nil {
+				t.Fatalf("%s/%s: %v", name, element.Name, err)
+			}
+			first, _ := Run("astar", SearchRequest{Target: element, Graph: graph, MaxPaths: 1})
+			astarNodes += first.Stats.NodesExpanded
+			bfsNodes += bfs.Stats.NodesExpanded
+		}
+		t.Logf("%s: astar expanded %d nodes, bfs %d", name, astarNodes, bfsNodes)
+	}
+}
+
+// The stats of a search for max trees cover exactly the max distinct trees handed out, no extra one
+func TestAStarStopsAtMax(t *testing.T) {
+	for name, graph := range testGraphs(t) {
+		target := deepestCraftable(graph)
+		for _, maxPaths := range []int{1, 3} {
+			req := SearchRequest{Target: target, Graph: graph, MaxPaths: maxPaths}
+			var pulled SearchStats
+			// Trees listing ingredients the other way round count once, as in collectTrees
+			distinct := make(treeSet)
+			for tree := range AStar(req, &pulled) {
+				if distinct.Add(tree) && len(distinct) == maxPaths {
+					break
+				}
+			}
+
+			result, err := Run("astar", req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Stats.NodesExpanded != pulled.NodesExpanded || !result.Stats.LimitHit {
+				t.Errorf("%s/%s max=%d: expanded %d nodes with limit hit %v, taking %d trees by hand expands %d",
+					name, target.Name, maxPaths, result.Stats.NodesExpanded, result.Stats.LimitHit, maxPaths, pulled.NodesExpanded)
+			}
+		}
+	}
+}
+
+// Every element of a dataset the size of the scraped one, within a time budget and without
+// hitting the state limit. With only the tier as its bound, a single search here used to
+// grow to gigabytes without finding a tree
+func TestAStarFinishesInBoundedTime(t *testing.T) {
+	graphs := testGraphs(t)
+	for seed := range uint64(3) {
+		graphs[fmt.Sprintf("synthetic-%d", seed)] = syntheticGraph(t, seed)
+	}
+
+	for name, graph := range graphs {
+		minimal := newMinimalTrees(graph)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for _, element := range graph.Elements[1:] {
+				for _, maxPaths := range []int{1, 10} {
+					result, err := Run("astar", SearchRequest{Target: element, Graph: graph, MaxPaths: maxPaths})
+					if err != nil {
+						t.Errorf("%s/%s max=%d: %v", name, element.Name, maxPaths, err)
+						continue
+					}
+					if minimal.of(element) == nil {
+						continue
+					}
+					if len(result.Trees) == 0 || result.Trees[0].Size() != minimal.size(element) {
+						t.Errorf("%s/%s: %d trees, the first is not the smallest", name, element.Name, len(result.Trees))
+					}
+				}
+			}
+		}()
+		select {
+		case <-done:
+		case <-time.After(30 * time.Second):
+			t.Fatalf("%s: astar did not search every element within 30s", name)
+		}
+	}
+}
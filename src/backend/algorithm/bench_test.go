@@ -0,0 +1,43 @@
+package algorithm
+
+import (
+	"backend/search"
+	"fmt"
+	"testing"
+)
+
+// go test ./algorithm -run ^$ -bench Search -benchmem
+// compares time and allocations of the tree search algorithms on the deepest element of each graph
+func BenchmarkSearch(b *testing.B) {
+	for name, graph := range testGraphs(b) {
+		target := deepestCraftable(graph)
+		for _, algo := range []string{"astar", "bfs", "dfs", "iddfs"} {
+			for _, maxPaths := range []int{1, 10} {
+				b.Run(fmt.Sprintf("%s/%s/%s/max=%d", name, target.Name, algo, maxPaths), func(b *testing.B) {
+					b.ReportAllocs()
+					var nodes int
+					for b.Loop() {
+						result, err := Run(algo, SearchRequest{Target: target, Graph: graph, MaxPaths: maxPaths})
+						if err != nil {
+							b.Fatal(err)
+						}
+						nodes = result.Stats.NodesExpanded
+					}
+					b.ReportMetric(float64(nodes), "nodes/op")
+				})
+			}
+		}
+	}
+}
+
+// Highest tier element that still has a recipe tree
+func deepestCraftable(graph *search.RecipeGraph) *search.ElementNode {
+	minimal := newMinimalTrees(graph)
+	var deepest *search.ElementNode
+	for _, element := range graph.Elements[1:] {
+		if minimal.of(element) != nil && (deepest == nil || element.Tier > deepest.Tier) {
+			deepest = element
+		}
+	}
+	return deepest
+}
@@ -112,15 +112,17 @@ type QueueItem struct {
 }
 
 type BFSProgressResult struct {
-	recipes      []JSONRecipe
-	nodes        []JSONNode
-	visitedNodes int
-	iteration    int
+	recipes   []JSONRecipe
+	nodes     []JSONNode
+	stats     SearchStats
+	iteration int
 }
 
-func ReverseBFS(target *search.ElementNode, pathNumber int) (*GraphJSONWithRecipes, int) {
+// observer may be nil
+func ReverseBFS(target *search.ElementNode, pathNumber int, observer Observer) (*GraphJSONWithRecipes, SearchStats) {
+	var stats SearchStats
 	if isBaseElement(target) {
-		return nil, 0
+		return nil, stats
 	}
 
 	// End result of the search
@@ -151,10 +153,10 @@ func ReverseBFS(target *search.ElementNode, pathNumber int) (*GraphJSONWithRecip
 
 	maxIterations := 1000
 	iteration := 0
-	visitedNodes := 0
 
 	nthreads := 4
 	for len(queue) > 0 && iteration < maxIterations {
+		stats.observeFrontier(len(queue))
 		nextFrontier := make([]QueueItem, 0)
 		taskChannel := make(chan QueueItem)
 		nextFrontierChannel := make(chan QueueItem)
@@ -164,16 +166,19 @@ func ReverseBFS(target *search.ElementNode, pathNumber int) (*GraphJSONWithRecip
 		progresses := make([]BFSProgressResult, nthreads)
 		for i := range nthreads {
 			progresses[i] = BFSProgressResult{
-				recipes:      make([]JSONRecipe, 0),
-				nodes:        make([]JSONNode, 0),
-				visitedNodes: 0,
-				iteration:    0,
+				recipes:   make([]JSONRecipe, 0),
+				nodes:     make([]JSONNode, 0),
+				iteration: 0,
 			}
-			go ProcessQueue(taskChannel, nextFrontierChannel, &progresses[i], &wg)
+			go ProcessQueue(taskChannel, nextFrontierChannel, &progresses[i], &wg, observer)
 		}
+		// Workers plus the frontier receiver below
+		stats.GoroutinesSpawned += nthreads + 1
 
 		// Receive results from routines
+		receiverDone := make(chan struct{})
 		go func() {
+			defer close(receiverDone)
 			for {
 				item, ok := <-nextFrontierChannel
 				if !ok {
@@ -191,9 +196,10 @@ func ReverseBFS(target *search.ElementNode, pathNumber int) (*GraphJSONWithRecip
 		// All routine done processing this level
 		wg.Wait()
 		close(nextFrontierChannel)
+		<-receiverDone
 		// Merge the results of each routines
 		for _, progress := range progresses {
-			visitedNodes += progress.visitedNodes
+			stats.merge(progress.stats)
 			iteration += progress.iteration
 
 			// Merge recipes uniquely
@@ -202,6 +208,8 @@ func ReverseBFS(target *search.ElementNode, pathNumber int) (*GraphJSONWithRecip
 				if _, exists := addedRecipe[recipeSignature]; !exists {
 					addedRecipe[recipeSignature] = true
 					recipes = append(recipes, recipe)
+				} else {
+					stats.DedupHits++
 				}
 			}
 			// Merge all used nodes in recipes
@@ -217,20 +225,21 @@ func ReverseBFS(target *search.ElementNode, pathNumber int) (*GraphJSONWithRecip
 	}
 
 	if iteration >= maxIterations {
+		stats.LimitHit = true
 		fmt.Printf("Warning: Reached max iterations (%d) for path %d\n", maxIterations, pathNumber)
 	}
 
 	return &GraphJSONWithRecipes{
 		Nodes:   nodes,
 		Recipes: recipes,
-	}, visitedNodes
+	}, stats
 }
 
 func ResetCaches() {
 	usedElemComb = make(map[string]map[string]bool)
 }
 
-func ProcessQueue(task chan QueueItem, next chan QueueItem, result *BFSProgressResult, wg *sync.WaitGroup) {
+func ProcessQueue(task chan QueueItem, next chan QueueItem, result *BFSProgressResult, wg *sync.WaitGroup, observer Observer) {
 	defer func() {
 		wg.Done()
 		// fmt.Println("Routine finished")
@@ -245,7 +254,6 @@ func ProcessQueue(task chan QueueItem, next chan QueueItem, result *BFSProgressR
 		// fmt.Println("Processing item:", item.Node.Name, "Depth:", item.Depth)
 
 		result.iteration++
-		result.visitedNodes++
 
 		if isBaseElement(item.Node) {
 			continue
@@ -253,26 +261,39 @@ func ProcessQueue(task chan QueueItem, next chan QueueItem, result *BFSProgressR
 		if isNoRecipe(item.Node) {
 			continue
 		}
+		result.stats.NodesExpanded++
+		notify(observer, SearchEvent{Type: EventNodeExpanded, Element: item.Node.Name, Depth: item.Depth})
 
 		for _, recipe := range item.Node.Recipes {
 			if len(recipe) != 2 || recipe[0] == nil || recipe[1] == nil {
 				continue
 			}
+			result.stats.RecipesConsidered++
+			pruned := SearchEvent{Type: EventRecipePruned, Element: item.Node.Name, Ingredients: recipeNames(recipe), Depth: item.Depth}
 
 			if (isNoRecipe(recipe[0]) && !isBaseElement(recipe[0])) || (isNoRecipe(recipe[1]) && !isBaseElement(recipe[1])) {
+				pruned.Reason = PruneUncraftable
+				notify(observer, pruned)
 				continue
 			}
 			if recipe[0].Tier >= item.Node.Tier || recipe[1].Tier >= item.Node.Tier {
+				result.stats.RecipesPrunedByTier++
+				pruned.Reason = PruneTier
+				notify(observer, pruned)
 				continue
 			}
 
 			usedElemCombMutex.Lock()
 			if isElemCombUsed(item.Node.Name, recipe[0].ID, recipe[1].ID, item.AncestryChain) {
 				usedElemCombMutex.Unlock()
+				result.stats.DedupHits++
+				pruned.Reason = PruneDuplicate
+				notify(observer, pruned)
 				continue
 			}
 			markElemCombUsed(item.Node.Name, recipe[0].ID, recipe[1].ID, item.AncestryChain)
 			usedElemCombMutex.Unlock()
+			notify(observer, SearchEvent{Type: EventRecipeAccepted, Element: item.Node.Name, Ingredients: recipeNames(recipe), Depth: item.Depth})
 
 			result.recipes = append(result.recipes, JSONRecipe{
 				Ingredients: []string{recipe[0].Name, recipe[1].Name},
@@ -301,8 +322,6 @@ func ProcessQueue(task chan QueueItem, next chan QueueItem, result *BFSProgressR
 						AncestryChain: newAncestry,
 						Depth:         item.Depth + 1,
 					}
-				} else {
-					result.visitedNodes++
 				}
 
 			}
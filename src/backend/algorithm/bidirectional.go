@@ -0,0 +1,159 @@
+package algorithm
+
+import (
+	"backend/search"
+	"errors"
+	"fmt"
+)
+
+var ErrNoRecipe = errors.New("no recipe found")
+
+// Shortest crafting chain source -> ... -> target, every element crafted from the previous one
+// plus some partner ingredient, and the full recipe tree of target built around that chain.
+// The search meets in the middle: Children edges forward from source, Recipes edges backward from target.
+func BidirectionalSearch(source, target *search.ElementNode, graph *search.RecipeGraph, observer Observer) (*RecipeTree, []string, SearchStats, error) {
+	var stats SearchStats
+	minimal := newMinimalTrees(graph)
+
+	if minimal.of(source) == nil {
+		return nil, nil, stats, fmt.Errorf("%w: %s cannot be crafted", ErrNoRecipe, source.Name)
+	}
+	if source == target {
+		return minimal.of(target), []string{target.Name}, stats, nil
+	}
+
+	// A step a -> b exists when b has a usable recipe with a and a craftable partner
+	usable := func(result, ingredient *search.ElementNode) bool {
+		stats.RecipesConsidered++
+		found := false
+		for _, recipe := range result.Recipes {
+			if recipe[0] != ingredient && recipe[1] != ingredient {
+				continue
+			}
+			if !search.UsableRecipe(result, recipe) {
+				stats.RecipesPrunedByTier++
+				continue
+			}
+			partner := recipe[0]
+			if partner == ingredient {
+				partner = recipe[1]
+			}
+			if minimal.of(partner) != nil {
+				found = true
+			}
+		}
+		return found
+	}
+
+	// next[x] is the element after x on the chain, prev[x] the one before it.
+	// fromSource and toTarget hold the number of steps found so far on each side
+	prev := map[*search.ElementNode]*search.ElementNode{source: nil}
+	next := map[*search.ElementNode]*search.ElementNode{target: nil}
+	fromSource := map[*search.ElementNode]int{source: 0}
+	toTarget := map[*search.ElementNode]int{target: 0}
+	forward := []*search.ElementNode{source}
+	backward := []*search.ElementNode{target}
+	forwardDepth, backwardDepth := 0, 0
+
+	var meet *search.ElementNode
+	for meet == nil && len(forward) > 0 && len(backward) > 0 {
+		stats.observeFrontier(len(forward) + len(backward))
+
+		// Expand the smaller side by one whole level
+		expandForward := len(forward) <= len(backward)
+		var level []*search.ElementNode
+		if expandForward {
+			forwardDepth++
+			for _, element := range forward {
+				stats.NodesExpanded++
+				notify(observer, SearchEvent{Type: EventNodeExpanded, Element: element.Name, Depth: -forwardDepth})
+				for _, child := range element.Children {
+					if _, seen := prev[child]; seen || !usable(child, element) {
+						continue
+					}
+					prev[child] = element
+					fromSource[child] = forwardDepth
+					level = append(level, child)
+				}
+			}
+			forward = level
+		} else {
+			backwardDepth++
+			for _, element := range backward {
+				stats.NodesExpanded++
+				notify(observer, SearchEvent{Type: EventNodeExpanded, Element: element.Name, Depth: backwardDepth})
+				for _, recipe := range element.Recipes {
+					for _, ingredient := range recipe {
+						if _, seen := next[ingredient]; seen || !usable(element, ingredient) {
+							continue
+						}
+						next[ingredient] = element
+						toTarget[ingredient] = backwardDepth
+						level = append(level, ingredient)
+					}
+				}
+			}
+			backward = level
+		}
+
+		// The first level that touches the other side contains a shortest chain
+		for _, element := range level {
+			stepsFrom, forwardSeen := fromSource[element]
+			stepsTo, backwardSeen := toTarget[element]
+			if !forwardSeen || !backwardSeen {
+				continue
+			}
+			if meet == nil || stepsFrom+stepsTo < fromSource[meet]+toTarget[meet] {
+				meet = element
+			}
+		}
+	}
+	if meet == nil {
+		return nil, nil, stats, fmt.Errorf("%w: %s cannot be crafted using %s", ErrNoRecipe, target.Name, source.Name)
+	}
+
+	chain := make([]*search.ElementNode, 0)
+	for element := meet; element != nil; element = prev[element] {
+		chain = append([]*search.ElementNode{element}, chain...)
+	}
+	for element := next[meet]; element != nil; element = next[element] {
+		chain = append(chain, element)
+	}
+
+	// Grow the tree along the chain, each step paired with the cheapest partner available
+	tree := minimal.of(source)
+	names := []string{source.Name}
+	for i := 1; i < len(chain); i++ {
+		ingredient, result := chain[i-1], chain[i]
+
+		var partner *search.ElementNode
+		for _, recipe := range result.Recipes {
+			if !search.UsableRecipe(result, recipe) || (recipe[0] != ingredient && recipe[1] != ingredient) {
+				continue
+			}
+			candidate := recipe[0]
+			if candidate == ingredient {
+				candidate = recipe[1]
+			}
+			size := minimal.size(candidate)
+			if size >= 0 && (partner == nil || size < minimal.size(partner)) {
+				partner = candidate
+			}
+		}
+
+		partnerTree := minimal.of(partner)
+		if partner == ingredient {
+			partnerTree = tree
+		}
+		tree = &RecipeTree{
+			ID:          result.ID,
+			Element:     result.Name,
+			Ingredients: []*RecipeTree{tree, partnerTree},
+		}
+		notify(observer, SearchEvent{Type: EventRecipeAccepted, Element: result.Name, Ingredients: []string{ingredient.Name, partner.Name}, Depth: len(chain) - 1 - i})
+		names = append(names, result.Name)
+	}
+
+	notify(observer, SearchEvent{Type: EventPathCompleted, Element: target.Name, Tree: tree})
+	return tree, names, stats, nil
+}
@@ -0,0 +1,76 @@
+package algorithm
+
+import (
+	"backend/search"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestBidirectionalFindsShortestChains(t *testing.T) {
+	graph := testGraphs(t)["fixture"]
+	tests := []struct {
+		source, target string
+		chain          int // Elements on the shortest chain, both ends included
+	}{
+		{"Fire", "Acid rain", 3}, // Fire, Stone through Mud + Fire, Acid rain
+		{"Water", "Acid rain", 3},
+		{"Earth", "Island", 4},
+		{"Air", "Cloud", 2},
+		{"Steam", "Steam", 1},
+	}
+	for _, test := range tests {
+		source, _ := search.GetElementByName(graph, test.source)
+		target, _ := search.GetElementByName(graph, test.target)
+		tree, chain, _, err := BidirectionalSearch(source, target, graph, nil)
+		if err != nil {
+			t.Errorf("%s to %s: %v", test.source, test.target, err)
+			continue
+		}
+		if len(chain) != test.chain || chain[0] != test.source || chain[len(chain)-1] != test.target {
+			t.Errorf("%s to %s: chain %q, want %d elements from one to the other", test.source, test.target, chain, test.chain)
+		}
+		if problem := checkTree(graph, tree); problem != "" {
+			t.Errorf("%s to %s: %s", test.source, test.target, problem)
+		}
+		// Every element of the chain, the source included, is crafted in the tree
+		crafted := make(map[string]bool)
+		tree.walkPostOrder(func(node *RecipeTree, depth int) { crafted[node.Element] = true }, 0)
+		for _, element := range chain {
+			if !crafted[element] {
+				t.Errorf("%s to %s: %s is on the chain but not in the tree", test.source, test.target, element)
+			}
+		}
+		if tree.Element != test.target {
+			t.Errorf("%s to %s: the tree is for %s", test.source, test.target, tree.Element)
+		}
+	}
+}
+
+func TestBidirectionalWithoutChain(t *testing.T) {
+	graph := testGraphs(t)["fixture"]
+	tests := [][2]string{
+		{"Acid rain", "Water"}, // Nothing is made from Acid rain
+		{"Stone", "Steam"},     // Steam is of a lower tier than Stone
+		{"Metal", "Island"},    // Island is made from Stone and Water only
+	}
+	for _, test := range tests {
+		source, _ := search.GetElementByName(graph, test[0])
+		target, _ := search.GetElementByName(graph, test[1])
+		tree, chain, _, err := BidirectionalSearch(source, target, graph, nil)
+		if !errors.Is(err, ErrNoRecipe) || tree != nil || chain != nil {
+			t.Errorf("%s to %s: got chain %q and error %v, want %v", test[0], test[1], chain, err, ErrNoRecipe)
+		}
+	}
+
+	// The registered searcher needs a source
+	target, _ := search.GetElementByName(graph, "Rain")
+	water, _ := search.GetElementByName(graph, "Water")
+	if _, err := Run("bidirectional", SearchRequest{Target: target, Graph: graph}); err == nil {
+		t.Error("bidirectional ran without a source")
+	}
+	if result, err := Run("bidirectional", SearchRequest{Target: target, Graph: graph, Source: water}); err != nil ||
+		!slices.Equal(result.Chain, []string{"Water", "Rain"}) {
+		t.Errorf("Water to Rain: chain %q and error %v", result.Chain, err)
+	}
+}
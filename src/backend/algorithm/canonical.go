@@ -0,0 +1,49 @@
+package algorithm
+
+import (
+	"sort"
+	"strings"
+)
+
+// CanonicalKey identifies a tree up to ingredient order.
+// Two trees have the same key exactly when they are structurally the same recipe tree.
+func (tree *RecipeTree) CanonicalKey() string {
+	if tree.IsLeaf() {
+		return tree.Element
+	}
+	keys := make([]string, len(tree.Ingredients))
+	for i, ingredient := range tree.Ingredients {
+		keys[i] = ingredient.CanonicalKey()
+	}
+	sort.Strings(keys)
+	return tree.Element + "(" + strings.Join(keys, "+") + ")"
+}
+
+// Canonical returns a copy of the tree with every ingredient pair ordered by canonical key
+func (tree *RecipeTree) Canonical() *RecipeTree {
+	canonical := &RecipeTree{ID: tree.ID, Element: tree.Element}
+	if tree.IsLeaf() {
+		return canonical
+	}
+
+	for _, ingredient := range tree.Ingredients {
+		canonical.Ingredients = append(canonical.Ingredients, ingredient.Canonical())
+	}
+	sort.SliceStable(canonical.Ingredients, func(i, j int) bool {
+		return canonical.Ingredients[i].CanonicalKey() < canonical.Ingredients[j].CanonicalKey()
+	})
+	return canonical
+}
+
+// Remembers which trees were already returned so every result is structurally distinct
+type treeSet map[string]struct{}
+
+// Add reports whether the tree was new
+func (set treeSet) Add(tree *RecipeTree) bool {
+	key := tree.CanonicalKey()
+	if _, exists := set[key]; exists {
+		return false
+	}
+	set[key] = struct{}{}
+	return true
+}
@@ -0,0 +1,88 @@
+package algorithm
+
+import (
+	"backend/search"
+	"slices"
+	"testing"
+)
+
+func TestCanonicalKeyIgnoresIngredientOrder(t *testing.T) {
+	steam := &RecipeTree{Element: "Steam", Ingredients: []*RecipeTree{{Element: "Fire"}, {Element: "Water"}}}
+	maets := &RecipeTree{Element: "Steam", Ingredients: []*RecipeTree{{Element: "Water"}, {Element: "Fire"}}}
+	cloud := &RecipeTree{Element: "Cloud", Ingredients: []*RecipeTree{steam, {Element: "Air"}}}
+	duolc := &RecipeTree{Element: "Cloud", Ingredients: []*RecipeTree{{Element: "Air"}, maets}}
+
+	if cloud.CanonicalKey() != duolc.CanonicalKey() {
+		t.Fatalf("keys differ: %s vs %s", cloud.CanonicalKey(), duolc.CanonicalKey())
+	}
+	if cloud.Canonical().String() != duolc.Canonical().String() {
+		t.Fatalf("canonical trees differ: %s vs %s", cloud.Canonical(), duolc.Canonical())
+	}
+
+	other := &RecipeTree{Element: "Cloud", Ingredients: []*RecipeTree{{Element: "Air"}, {Element: "Steam", Ingredients: []*RecipeTree{{Element: "Air"}, {Element: "Water"}}}}}
+	if cloud.CanonicalKey() == other.CanonicalKey() {
+		t.Fatalf("different trees share key %s", other.CanonicalKey())
+	}
+}
+
+// Every returned tree must be a real crafting tree and no two may be the same up to ingredient order
+func TestSearchTreesAreDistinctForEveryElement(t *testing.T) {
+	for name, graph := range testGraphs(t) {
+		for _, algo := range []string{"astar", "bfs", "dfs", "iddfs"} {
+			for _, element := range graph.Elements[1:] {
+				result, err := Run(algo, SearchRequest{Target: element, Graph: graph, MaxPaths: 25})
+				if err != nil {
+					t.Fatalf("%s/%s/%s: %v", name, algo, element.Name, err)
+				}
+
+				seen := make(map[string]bool)
+				for _, tree := range result.Trees {
+					if err := checkTree(graph, tree); err != "" {
+						t.Errorf("%s/%s/%s: invalid tree %s: %s", name, algo, element.Name, tree, err)
+					}
+					key := tree.CanonicalKey()
+					if seen[key] {
+						t.Errorf("%s/%s/%s: duplicate tree %s", name, algo, element.Name, key)
+					}
+					seen[key] = true
+				}
+			}
+		}
+	}
+}
+
+// Leaves must be base elements and every combination must be one of the element's recipes
+func checkTree(graph *search.RecipeGraph, tree *RecipeTree) string {
+	node, err := search.GetElementByName(graph, tree.Element)
+	if err != nil {
+		return err.Error()
+	}
+	if tree.IsLeaf() {
+		if !slices.Contains(graph.BaseElements, node) {
+			return tree.Element + " is a leaf but not a base element"
+		}
+		return ""
+	}
+	if len(tree.Ingredients) != 2 {
+		return tree.Element + " does not have two ingredients"
+	}
+
+	a, b := tree.Ingredients[0].Element, tree.Ingredients[1].Element
+	found := false
+	for _, recipe := range node.Recipes {
+		if (recipe[0].Name == a && recipe[1].Name == b) || (recipe[0].Name == b && recipe[1].Name == a) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return tree.Element + " has no recipe " + a + " + " + b
+	}
+
+	for _, ingredient := range tree.Ingredients {
+		if err := checkTree(graph, ingredient); err != "" {
+			return err
+		}
+	}
+	return ""
+}
@@ -0,0 +1,203 @@
+package algorithm
+
+import (
+	"backend/search"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Elements every returned tree must contain, and elements no returned tree may contain
+type Constraints struct {
+	Include []*search.ElementNode
+	Exclude []*search.ElementNode
+}
+
+// Trees skipped for not containing the included elements before a search gives up
+const maxConstraintRejects = 100000
+
+// Returned when a search gave up before finding a single tree, after rejecting too many trees
+// without the included elements or after outgrowing its state limit. Unlike a
+// *ConstraintError a tree may still exist
+var ErrSearchLimit = errors.New("search limit reached")
+
+func (c Constraints) IsEmpty() bool { return len(c.Include) == 0 && len(c.Exclude) == 0 }
+
+func (c Constraints) excludes(element string) bool {
+	for _, excluded := range c.Exclude {
+		if excluded.Name == element {
+			return true
+		}
+	}
+	return false
+}
+
+// A recipe is allowed when none of its ingredients is excluded
+func (c Constraints) allowsRecipe(recipe []*search.ElementNode) bool {
+	for _, ingredient := range recipe {
+		if slices.Contains(c.Exclude, ingredient) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c Constraints) satisfiedBy(tree *RecipeTree) bool {
+	return len(c.violatedBy(tree)) == 0
+}
+
+// Excluded elements the tree contains and included elements it lacks
+func (c Constraints) violatedBy(tree *RecipeTree) []string {
+	present := make(map[string]bool)
+	tree.walkPostOrder(func(node *RecipeTree, depth int) {
+		present[node.Element] = true
+	}, 0)
+
+	violations := make([]string, 0)
+	for _, excluded := range c.Exclude {
+		if present[excluded.Name] {
+			violations = append(violations, excluded.Name)
+		}
+	}
+	for _, included := range c.Include {
+		if !present[included.Name] {
+			violations = append(violations, included.Name)
+		}
+	}
+	return violations
+}
+
+// For every included element, the elements with a usable recipe tree that contains it.
+// Searches use it to skip recipes that cannot lead to a tree holding every included element,
+// the trees they find are still checked against the constraints
+type inclusion map[*search.ElementNode]map[*search.ElementNode]bool
+
+func newInclusion(graph *search.RecipeGraph, constraints Constraints) inclusion {
+	reach := make(inclusion, len(constraints.Include))
+	for _, included := range constraints.Include {
+		reach[included] = craftableUsing(graph, included, constraints.Exclude)
+	}
+	return reach
+}
+
+// Whether every missing element can still end up in a tree of one of elements
+func (reach inclusion) possible(missing []*search.ElementNode, elements ...*search.ElementNode) bool {
+	for _, included := range missing {
+		if !slices.ContainsFunc(elements, func(element *search.ElementNode) bool { return reach[included][element] }) {
+			return false
+		}
+	}
+	return true
+}
+
+// The missing elements only a tree of element can supply, when other makes the rest of the tree
+func (reach inclusion) onlyFrom(missing []*search.ElementNode, other *search.ElementNode) []*search.ElementNode {
+	return slices.DeleteFunc(slices.Clone(missing), func(included *search.ElementNode) bool { return reach[included][other] })
+}
+
+// missing without the given elements. Never modifies missing, trees share it.
+// Used with graph nodes and with the element names of the ReverseBFS graph alike
+func without[E comparable](missing []E, elements ...E) []E {
+	if !slices.ContainsFunc(missing, func(included E) bool { return slices.Contains(elements, included) }) {
+		return missing
+	}
+	return slices.DeleteFunc(slices.Clone(missing), func(included E) bool { return slices.Contains(elements, included) })
+}
+
+// The missing elements tree does not contain, name tells which element each one is
+func lacking[E any](tree *RecipeTree, missing []E, name func(E) string) []E {
+	if len(missing) == 0 {
+		return missing
+	}
+	present := make(map[string]bool)
+	tree.walkPostOrder(func(node *RecipeTree, depth int) {
+		present[node.Element] = true
+	}, 0)
+	return slices.DeleteFunc(slices.Clone(missing), func(included E) bool { return present[name(included)] })
+}
+
+func nodeName(element *search.ElementNode) string { return element.Name }
+
+// Returned when the constraints leave no recipe tree for the target
+type ConstraintError struct {
+	Target   string
+	Reason   string
+	Blocking []string // The elements that make the target unreachable
+}
+
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("%s is unreachable under the given constraints: %s (%s)", e.Target, e.Reason, strings.Join(e.Blocking, ", "))
+}
+
+func (e *ConstraintError) Unwrap() error { return ErrNoRecipe }
+
+func names(elements []*search.ElementNode) []string {
+	out := make([]string, len(elements))
+	for i, element := range elements {
+		out[i] = element.Name
+	}
+	return out
+}
+
+// Elements that can be crafted without touching excluded ones
+func craftableWithout(graph *search.RecipeGraph, exclude []*search.ElementNode) map[*search.ElementNode]bool {
+	return search.TierFold[bool]{
+		Skip:   func(element *search.ElementNode) bool { return slices.Contains(exclude, element) },
+		Base:   func(*search.ElementNode) bool { return true },
+		Recipe: func(*search.ElementNode, []*search.ElementNode, bool, bool) bool { return true },
+	}.Run(graph)
+}
+
+// Craftable elements without touching excluded ones, mapped to whether one of their usable
+// recipe trees contains source
+func craftableUsing(graph *search.RecipeGraph, source *search.ElementNode, exclude []*search.ElementNode) map[*search.ElementNode]bool {
+	return search.TierFold[bool]{
+		Skip: func(element *search.ElementNode) bool { return slices.Contains(exclude, element) },
+		Base: func(element *search.ElementNode) bool { return element == source },
+		Recipe: func(element *search.ElementNode, _ []*search.ElementNode, left, right bool) bool {
+			return element == source || left || right
+		},
+		Better: func(value, current bool) bool { return value && !current },
+	}.Run(graph)
+}
+
+// CheckConstraints reports a *ConstraintError when no tree for target can satisfy the constraints.
+// Every included element is checked on its own, so a combination that only fails together passes here
+func CheckConstraints(target *search.ElementNode, graph *search.RecipeGraph, constraints Constraints) error {
+	if constraints.IsEmpty() {
+		return nil
+	}
+	if slices.Contains(constraints.Exclude, target) {
+		return &ConstraintError{Target: target.Name, Reason: "the target itself is excluded", Blocking: []string{target.Name}}
+	}
+
+	craftable := craftableWithout(graph, constraints.Exclude)
+	if !craftable[target] {
+		// Prefer the exclusions that alone cut the target off
+		blocking := make([]string, 0)
+		for _, excluded := range constraints.Exclude {
+			rest := slices.DeleteFunc(slices.Clone(constraints.Exclude), func(e *search.ElementNode) bool { return e == excluded })
+			if craftableWithout(graph, rest)[target] {
+				blocking = append(blocking, excluded.Name)
+			}
+		}
+		if len(blocking) == 0 {
+			blocking = names(constraints.Exclude)
+		}
+		return &ConstraintError{Target: target.Name, Reason: "every recipe needs an excluded element", Blocking: blocking}
+	}
+
+	for _, included := range constraints.Include {
+		if slices.Contains(constraints.Exclude, included) {
+			return &ConstraintError{Target: target.Name, Reason: "an element is both included and excluded", Blocking: []string{included.Name}}
+		}
+		if !craftable[included] {
+			return &ConstraintError{Target: target.Name, Reason: "an included element needs an excluded element", Blocking: []string{included.Name}}
+		}
+		if !craftableUsing(graph, included, constraints.Exclude)[target] {
+			return &ConstraintError{Target: target.Name, Reason: "an included element is not part of any recipe tree of the target", Blocking: []string{included.Name}}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package algorithm
+
+import (
+	"backend/scraping"
+	"backend/search"
+	"fmt"
+	"testing"
+)
+
+// Elements C1 to Cn, each made from the one before and Air or Earth. Only the last recipe of
+// Cn uses Water, so Cn has 2^n trees and the ones holding Water come after all the others
+func chainGraph(t *testing.T, n int) *search.RecipeGraph {
+	t.Helper()
+	recipes := scraping.RecipeEntry{
+		Element: []string{"Air", "Earth", "Fire", "Water"},
+		Recipe:  map[string][][]string{"Air": {{"", ""}}, "Earth": {{"", ""}}, "Fire": {{"", ""}}, "Water": {{"", ""}}},
+		Tiering: map[string]int{"Air": 0, "Earth": 0, "Fire": 0, "Water": 0},
+	}
+	previous := "Earth"
+	for k := 1; k <= n; k++ {
+		name := fmt.Sprintf("C%d", k)
+		recipes.Element = append(recipes.Element, name)
+		recipes.Tiering[name] = k
+		recipes.Recipe[name] = [][]string{{previous, "Air"}, {previous, "Earth"}}
+		if k == 1 {
+			recipes.Recipe[name] = [][]string{{"Air", "Earth"}, {"Air", "Fire"}}
+		}
+		previous = name
+	}
+	last := fmt.Sprintf("C%d", n)
+	recipes.Recipe[last] = append(recipes.Recipe[last], []string{fmt.Sprintf("C%d", n-1), "Water"})
+
+	var graph search.RecipeGraph
+	if err := search.ConstructRecipeGraph(recipes, &graph); err != nil {
+		t.Fatal(err)
+	}
+	return &graph
+}
+
+// More trees miss the included elements than a search may reject, so they are only found when
+// the searches leave out what cannot hold them
+func TestIncludeFindsLateTrees(t *testing.T) {
+	graph := chainGraph(t, 18)
+	target, err := search.GetElementByName(graph, "C18")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Fire only comes with the second recipe of C1, so it is needed at both ends of the tree
+	includes := [][]string{{"Water"}, {"Water", "Fire"}}
+	for _, algo := range []string{"bfs", "dfs", "iddfs", "astar"} {
+		for _, included := range includes {
+			include := make([]*search.ElementNode, len(included))
+			for i, name := range included {
+				if include[i], err = search.GetElementByName(graph, name); err != nil {
+					t.Fatal(err)
+				}
+			}
+			constraints := Constraints{Include: include}
+			result, err := Run(algo, SearchRequest{Target: target, Graph: graph, MaxPaths: 3, Constraints: constraints})
+			if err != nil {
+				t.Errorf("%s with %v: %v", algo, included, err)
+				continue
+			}
+			if len(result.Trees) != 3 {
+				t.Errorf("%s with %v: %d trees, want 3", algo, included, len(result.Trees))
+			}
+			for _, tree := range result.Trees {
+				if missing := constraints.violatedBy(tree); len(missing) > 0 {
+					t.Errorf("%s with %v: a tree misses %v", algo, included, missing)
+				}
+			}
+		}
+	}
+}
@@ -35,15 +35,23 @@ type Recipe struct {
 
 type PathResult map[string]RecipeJSON
 
-func DFS(target *search.ElementNode, graph *search.RecipeGraph, maxPaths int, nodeVisited *int) []PathResult {
-	if maxPaths == 1 {
+func DFS(req SearchRequest, stats *SearchStats) []*RecipeTree {
+	// Constraints may reject the first tree found, so they need the multi-path search to move on
+	if req.MaxPaths == 1 && req.Constraints.IsEmpty() {
 		result := &ResultTree{path: make([]*Recipe, 0)}
-		findSinglePath(target, graph, result, nodeVisited)
+		root := findSinglePath(req.Target, req.Graph, result, stats, req.Observer, 0)
+		if root == nil {
+			return []*RecipeTree{}
+		}
 
-		return []PathResult{ParseCraftingPathToJSON(result, graph)}
+		stats.LimitHit = true
+		tree := treeFromRecipe(root)
+		notify(req.Observer, SearchEvent{Type: EventPathCompleted, Element: tree.Element, Tree: tree})
+		req.emit(tree)
+		return []*RecipeTree{tree}
 	}
 
-	return findMultiplePaths(target, graph, maxPaths, nodeVisited)
+	return findMultiplePaths(req, stats)
 }
 
 func mergeTree(tree0 *ResultTree, tree1 *ResultTree, resulto *ResultTree) {
@@ -53,8 +61,8 @@ func mergeTree(tree0 *ResultTree, tree1 *ResultTree, resulto *ResultTree) {
 
 /* ----------------------------------------- Single Recipe DFS ----------------------------------------------- */
 
-func findSinglePath(target *search.ElementNode, graph *search.RecipeGraph, result *ResultTree, nodeVisited *int) *Recipe {
-	*nodeVisited++
+func findSinglePath(target *search.ElementNode, graph *search.RecipeGraph, result *ResultTree, stats *SearchStats, observer Observer, depth int) *Recipe {
+	stats.observeFrontier(depth + 1)
 
 	if slices.Contains(graph.BaseElements, target) {
 		*result = ResultTree{path: make([]*Recipe, 0)}
@@ -66,23 +74,35 @@ func findSinglePath(target *search.ElementNode, graph *search.RecipeGraph, resul
 	if target.Name == "Time" {
 		return nil
 	}
+	stats.NodesExpanded++
+	notify(observer, SearchEvent{Type: EventNodeExpanded, Element: target.Name, Depth: depth})
 
 	// Try each recipe
 	for _, recipe := range target.Recipes {
+		stats.RecipesConsidered++
+		pruned := SearchEvent{Type: EventRecipePruned, Element: target.Name, Ingredients: recipeNames(recipe), Depth: depth}
 		if recipe[0].Tier >= target.Tier || recipe[1].Tier >= target.Tier {
+			stats.RecipesPrunedByTier++
+			pruned.Reason = PruneTier
+			notify(observer, pruned)
 			continue
 		}
 
 		result0 := &ResultTree{path: make([]*Recipe, 0)}
-		component0 := findSinglePath(recipe[0], graph, result0, nodeVisited)
+		component0 := findSinglePath(recipe[0], graph, result0, stats, observer, depth+1)
 		if component0 == nil {
+			pruned.Reason = PruneDeadEnd
+			notify(observer, pruned)
 			continue
 		}
 		result1 := &ResultTree{path: make([]*Recipe, 0)}
-		component1 := findSinglePath(recipe[1], graph, result1, nodeVisited)
+		component1 := findSinglePath(recipe[1], graph, result1, stats, observer, depth+1)
 		if component1 == nil {
+			pruned.Reason = PruneDeadEnd
+			notify(observer, pruned)
 			continue
 		}
+		notify(observer, SearchEvent{Type: EventRecipeAccepted, Element: target.Name, Ingredients: recipeNames(recipe), Depth: depth})
 
 		mergeTree(result0, result1, result)
 		validRecipe := &Recipe{
@@ -106,11 +126,31 @@ type SearchStatus struct {
 type SearchStatistic struct {
 	mu             sync.Mutex
 	remainingPaths int
-	nodeVisited    int
+	liveSearches   int // findPath goroutines currently running
+	stats          SearchStats
+	observer       Observer
+	constraints    Constraints
+	include        inclusion
+}
+
+// Count a findPath goroutine that is about to be started
+func (s *SearchStatistic) spawn() {
+	s.mu.Lock()
+	s.stats.GoroutinesSpawned++
+	s.liveSearches++
+	s.stats.observeFrontier(s.liveSearches)
+	s.mu.Unlock()
 }
 
-func findMultiplePaths(target *search.ElementNode, graph *search.RecipeGraph, maxPaths int, nodeVisited *int) []PathResult {
-	resultJSONs := make([]PathResult, 0, maxPaths)
+func (s *SearchStatistic) exit() {
+	s.mu.Lock()
+	s.liveSearches--
+	s.mu.Unlock()
+}
+
+func findMultiplePaths(req SearchRequest, searchStats *SearchStats) []*RecipeTree {
+	maxPaths := req.MaxPaths
+	trees := make([]*RecipeTree, 0)
 
 	status := SearchStatus{
 		result:         make(chan int),
@@ -119,22 +159,53 @@ func findMultiplePaths(target *search.ElementNode, graph *search.RecipeGraph, ma
 	stats := &SearchStatistic{
 		mu:             sync.Mutex{},
 		remainingPaths: maxPaths,
-		nodeVisited:    0,
+		observer:       req.Observer,
+		constraints:    req.Constraints,
+		include:        newInclusion(req.Graph, req.Constraints),
 	}
 	result := &ResultTree{path: make([]*Recipe, 0)}
 
-	go findPath(target, graph, result, status, stats)
+	stats.spawn()
+	go findPath(req.Target, req.Graph, result, status, stats, 0, req.Constraints.Include)
 
 	counter := 0
+	rejected := 0
+	distinct := make(treeSet)
 	condition := <-status.result
 	for condition != 0 {
+		// The search goroutines reuse result, so convert it before letting them continue
+		tree := treeFromRecipe(result.path[0])
+		if !distinct.Add(tree) {
+			stats.mu.Lock()
+			stats.stats.DedupHits++
+			stats.mu.Unlock()
+			status.continueSignal <- 1
+			condition = <-status.result
+			continue
+		}
+		if !req.Constraints.satisfiedBy(tree) {
+			rejected++
+			if rejected > maxConstraintRejects {
+				stats.mu.Lock()
+				stats.stats.LimitHit = true
+				stats.mu.Unlock()
+				status.continueSignal <- 0
+				<-status.result
+				break
+			}
+			status.continueSignal <- 1
+			condition = <-status.result
+			continue
+		}
 		counter++
-
-		// Convert result to JSON instead of writing to file
-		pathJSON := ParseCraftingPathToJSON(result, graph)
-		resultJSONs = append(resultJSONs, pathJSON)
-
-		if counter >= maxPaths {
+		trees = append(trees, tree)
+		notify(req.Observer, SearchEvent{Type: EventPathCompleted, Element: tree.Element, Tree: tree})
+		wanted := req.emit(tree)
+
+		if counter >= maxPaths || !wanted {
+			stats.mu.Lock()
+			stats.stats.LimitHit = true
+			stats.mu.Unlock()
 			status.continueSignal <- 0
 			<-status.result
 			break
@@ -145,15 +216,15 @@ func findMultiplePaths(target *search.ElementNode, graph *search.RecipeGraph, ma
 	}
 
 	stats.mu.Lock()
-	*nodeVisited = stats.nodeVisited
+	searchStats.merge(stats.stats)
 	stats.mu.Unlock()
-	return resultJSONs
+	return trees
 }
 
-func findPath(target *search.ElementNode, graph *search.RecipeGraph, result *ResultTree, status SearchStatus, stats *SearchStatistic) {
-	stats.mu.Lock()
-	stats.nodeVisited++
-	stats.mu.Unlock()
+// Yields the trees of target one at a time over status. Recipes through which the needed elements
+// cannot all be reached are skipped, the trees yielded may still miss some of them
+func findPath(target *search.ElementNode, graph *search.RecipeGraph, result *ResultTree, status SearchStatus, stats *SearchStatistic, depth int, need []*search.ElementNode) {
+	defer stats.exit()
 
 	// Base case: if the target is a base element, return
 	if slices.Contains(graph.BaseElements, target) {
@@ -172,24 +243,52 @@ func findPath(target *search.ElementNode, graph *search.RecipeGraph, result *Res
 		status.result <- 0
 		return
 	}
+	stats.mu.Lock()
+	stats.stats.NodesExpanded++
+	stats.mu.Unlock()
+	notify(stats.observer, SearchEvent{Type: EventNodeExpanded, Element: target.Name, Depth: depth})
 
+recipes:
 	for _, recipe := range target.Recipes {
+		stats.mu.Lock()
+		stats.stats.RecipesConsidered++
 		if recipe[0].Tier >= target.Tier || recipe[1].Tier >= target.Tier {
+			stats.stats.RecipesPrunedByTier++
+			stats.mu.Unlock()
+			notify(stats.observer, SearchEvent{Type: EventRecipePruned, Element: target.Name, Ingredients: recipeNames(recipe), Depth: depth, Reason: PruneTier})
+			continue
+		}
+		stats.mu.Unlock()
+		if !stats.constraints.allowsRecipe(recipe) {
+			notify(stats.observer, SearchEvent{Type: EventRecipePruned, Element: target.Name, Ingredients: recipeNames(recipe), Depth: depth, Reason: PruneExcluded})
 			continue
 		}
+		rest := without(need, target)
+		if !stats.include.possible(rest, recipe...) {
+			notify(stats.observer, SearchEvent{Type: EventRecipePruned, Element: target.Name, Ingredients: recipeNames(recipe), Depth: depth, Reason: PruneIncluded})
+			continue
+		}
+		// Each side has to supply what the other cannot
+		need0, need1 := stats.include.onlyFrom(rest, recipe[1]), stats.include.onlyFrom(rest, recipe[0])
 
 		status0 := SearchStatus{result: make(chan int), continueSignal: make(chan int)}
 		result0 := &ResultTree{path: make([]*Recipe, 0)}
-		go findPath(recipe[0], graph, result0, status0, stats)
+		stats.spawn()
+		go findPath(recipe[0], graph, result0, status0, stats, depth+1, need0)
 
 		status1 := SearchStatus{result: make(chan int), continueSignal: make(chan int)}
 		result1 := &ResultTree{path: make([]*Recipe, 0)}
-		go findPath(recipe[1], graph, result1, status1, stats)
+		stats.spawn()
+		go findPath(recipe[1], graph, result1, status1, stats, depth+1, need1)
 
 		condition0 := <-status0.result
 		condition1 := <-status1.result
+		if condition0 == 0 || condition1 == 0 {
+			notify(stats.observer, SearchEvent{Type: EventRecipePruned, Element: target.Name, Ingredients: recipeNames(recipe), Depth: depth, Reason: PruneDeadEnd})
+		}
 		for condition0 != 0 && condition1 != 0 {
 			if condition0 == 1 && condition1 == 1 {
+				notify(stats.observer, SearchEvent{Type: EventRecipeAccepted, Element: target.Name, Ingredients: recipeNames(recipe), Depth: depth})
 				recipe := &Recipe{
 					element:     target,
 					composition: []*Recipe{result0.path[0], result1.path[0]},
@@ -209,7 +308,8 @@ func findPath(target *search.ElementNode, graph *search.RecipeGraph, result *Res
 					<-status0.result
 					<-status1.result
 
-					break
+					// The caller asked to stop, leave the remaining recipes unexplored
+					break recipes
 				} else {
 					status0.continueSignal <- 1
 					condition0 = <-status0.result
@@ -219,7 +319,8 @@ func findPath(target *search.ElementNode, graph *search.RecipeGraph, result *Res
 
 				status0 = SearchStatus{result: make(chan int), continueSignal: make(chan int)}
 				result0 = &ResultTree{path: make([]*Recipe, 0)}
-				go findPath(recipe[0], graph, result0, status0, stats)
+				stats.spawn()
+				go findPath(recipe[0], graph, result0, status0, stats, depth+1, need0)
 
 				condition0 = <-status0.result
 				condition1 = <-status1.result
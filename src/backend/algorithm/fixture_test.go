@@ -0,0 +1,119 @@
+package algorithm
+
+import (
+	"backend/scraping"
+	"backend/search"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"testing"
+)
+
+// Small hand written dataset shaped like the scraped one. Steam lists both ingredient
+// orders and Lava is reachable at several depths, the two sources of duplicate BFS trees
+var fixtureRecipes = scraping.RecipeEntry{
+	Element: []string{
+		"Air", "Earth", "Fire", "Water",
+		"Steam", "Lava", "Dust", "Mud", "Energy", "Pressure",
+		"Stone", "Cloud", "Rain", "Metal", "Acid rain", "Island",
+	},
+	Recipe: map[string][][]string{
+		"Air":       {{"", ""}},
+		"Earth":     {{"", ""}},
+		"Fire":      {{"", ""}},
+		"Water":     {{"", ""}},
+		"Steam":     {{"Fire", "Water"}, {"Water", "Fire"}, {"Air", "Water"}},
+		"Lava":      {{"Earth", "Fire"}},
+		"Dust":      {{"Earth", "Air"}},
+		"Mud":       {{"Earth", "Water"}, {"Dust", "Water"}},
+		"Energy":    {{"Fire", "Air"}, {"Fire", "Fire"}},
+		"Pressure":  {{"Air", "Air"}, {"Earth", "Earth"}},
+		"Stone":     {{"Lava", "Air"}, {"Lava", "Pressure"}, {"Mud", "Fire"}},
+		"Cloud":     {{"Steam", "Air"}, {"Steam", "Pressure"}},
+		"Rain":      {{"Cloud", "Water"}, {"Cloud", "Steam"}},
+		"Metal":     {{"Stone", "Fire"}, {"Stone", "Energy"}},
+		"Acid rain": {{"Rain", "Metal"}, {"Rain", "Stone"}},
+		"Island":    {{"Stone", "Water"}, {"Water", "Stone"}},
+	},
+	Tiering: map[string]int{
+		"Air": 0, "Earth": 0, "Fire": 0, "Water": 0,
+		"Steam": 1, "Lava": 1, "Dust": 1, "Energy": 1, "Pressure": 1,
+		"Mud": 2, "Cloud": 2, "Stone": 3, "Rain": 3, "Metal": 4, "Island": 4, "Acid rain": 5,
+	},
+}
+
+// The fixture graph, plus the scraped dataset when scraping/recipes.json is present
+func testGraphs(t testing.TB) map[string]*search.RecipeGraph {
+	t.Helper()
+	graphs := make(map[string]*search.RecipeGraph)
+
+	var fixture search.RecipeGraph
+	if err := search.ConstructRecipeGraph(fixtureRecipes, &fixture); err != nil {
+		t.Fatal(err)
+	}
+	graphs["fixture"] = &fixture
+
+	file, err := os.Open("../scraping/recipes.json")
+	if err != nil {
+		return graphs
+	}
+	defer file.Close()
+
+	var scraped scraping.RecipeEntry
+	if err := json.NewDecoder(file).Decode(&scraped); err != nil {
+		t.Fatal(err)
+	}
+	var dataset search.RecipeGraph
+	if err := search.ConstructRecipeGraph(scraped, &dataset); err != nil {
+		t.Fatal(err)
+	}
+	graphs["dataset"] = &dataset
+	return graphs
+}
+
+// Elements per tier of the generated dataset, about as many as the scraped one has
+var syntheticTiers = []int{4, 30, 50, 60, 70, 70, 70, 65, 60, 55, 50, 40, 30, 25, 20, 15}
+
+// A generated dataset the size and shape of the scraped one, for tests that must finish in
+// bounded time whether or not scraping/recipes.json is present. Every element has one to eight
+// recipes, each combining the tier just below with any lower tier, plus an occasional recipe
+// of the element's own tier that the tier rule prunes
+func syntheticGraph(t testing.TB, seed uint64) *search.RecipeGraph {
+	t.Helper()
+	random := rand.New(rand.NewPCG(seed, 0))
+	recipes := scraping.RecipeEntry{Recipe: make(map[string][][]string), Tiering: make(map[string]int)}
+	byTier := make([][]string, len(syntheticTiers))
+	for tier, count := range syntheticTiers {
+		for i := range count {
+			name := fmt.Sprintf("T%d-%d", tier, i)
+			if tier == 0 {
+				name = []string{"Air", "Earth", "Fire", "Water"}[i]
+				recipes.Recipe[name] = [][]string{{"", ""}}
+			}
+			recipes.Element = append(recipes.Element, name)
+			recipes.Tiering[name] = tier
+			byTier[tier] = append(byTier[tier], name)
+		}
+	}
+
+	pick := func(tier int) string { return byTier[tier][random.IntN(len(byTier[tier]))] }
+	for tier := 1; tier < len(byTier); tier++ {
+		for _, name := range byTier[tier] {
+			for range 1 + random.IntN(8) {
+				pair := []string{pick(tier - 1), pick(random.IntN(tier))}
+				if random.IntN(10) == 0 {
+					pair[1] = pick(tier)
+				}
+				random.Shuffle(2, func(i, j int) { pair[i], pair[j] = pair[j], pair[i] })
+				recipes.Recipe[name] = append(recipes.Recipe[name], pair)
+			}
+		}
+	}
+
+	var graph search.RecipeGraph
+	if err := search.ConstructRecipeGraph(recipes, &graph); err != nil {
+		t.Fatal(err)
+	}
+	return &graph
+}
@@ -0,0 +1,157 @@
+package algorithm
+
+import (
+	"backend/search"
+	"iter"
+	"slices"
+)
+
+// Iterative deepening over recipe trees: every pass enumerates the trees of exactly one depth,
+// so trees come out shallowest first. Single threaded and no tree is memoized, memory stays
+// proportional to the depth limit at the cost of re-walking the shallow levels on every pass.
+// Only the depth of every element's shallowest tree is computed up front, so passes and
+// ingredients that cannot reach the current limit are skipped instead of enumerated
+type deepening struct {
+	graph       *search.RecipeGraph
+	constraints Constraints
+	observer    Observer
+	stats       *SearchStats
+	depths      map[*search.ElementNode]int // Depth of the shallowest tree, see recipeTiers
+	include     inclusion
+}
+
+// IDDFS yields the recipe trees of target in order of increasing depth
+func IDDFS(req SearchRequest, stats *SearchStats) iter.Seq[*RecipeTree] {
+	d := &deepening{
+		graph:       req.Graph,
+		constraints: req.Constraints,
+		observer:    req.Observer,
+		stats:       stats,
+		depths:      recipeTiers(req.Graph, req.Constraints),
+		include:     newInclusion(req.Graph, req.Constraints),
+	}
+	return func(yield func(*RecipeTree) bool) {
+		shallowest, craftable := d.depths[req.Target]
+		if !craftable {
+			return
+		}
+		// Ingredients are always of a lower tier, so no tree is deeper than the target's tier
+		for limit := shallowest; limit <= req.Target.Tier; limit++ {
+			for tree := range d.exact(req.Target, limit, 0, req.Constraints.Include) {
+				if !yield(tree) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Trees of element whose depth is exactly limit. Trees that cannot contain every needed
+// element are skipped, but not every tree yielded is sure to contain them all
+func (d *deepening) exact(element *search.ElementNode, limit int, level int, need []*search.ElementNode) iter.Seq[*RecipeTree] {
+	return func(yield func(*RecipeTree) bool) {
+		d.stats.observeFrontier(level + 1)
+
+		if !d.include.possible(need, element) {
+			return
+		}
+		if slices.Contains(d.graph.BaseElements, element) {
+			if limit == 0 {
+				yield(&RecipeTree{ID: element.ID, Element: element.Name})
+			}
+			return
+		}
+		if !d.reaches(element, limit) || limit > element.Tier {
+			return
+		}
+		d.stats.NodesExpanded++
+		notify(d.observer, SearchEvent{Type: EventNodeExpanded, Element: element.Name, Depth: level})
+
+		for _, recipe := range element.Recipes {
+			d.stats.RecipesConsidered++
+			pruned := SearchEvent{Type: EventRecipePruned, Element: element.Name, Ingredients: recipeNames(recipe), Depth: level}
+			if !search.UsableRecipe(element, recipe) {
+				d.stats.RecipesPrunedByTier++
+				pruned.Reason = PruneTier
+				notify(d.observer, pruned)
+				continue
+			}
+			if !d.constraints.allowsRecipe(recipe) {
+				pruned.Reason = PruneExcluded
+				notify(d.observer, pruned)
+				continue
+			}
+			rest := without(need, element)
+			if !d.include.possible(rest, recipe...) {
+				pruned.Reason = PruneIncluded
+				notify(d.observer, pruned)
+				continue
+			}
+			// What the right side cannot supply has to come from the left
+			leftNeed := d.include.onlyFrom(rest, recipe[1])
+
+			// One side reaches limit-1 exactly and the other stays within it. Splitting on the
+			// left side keeps every pair in exactly one of the two loops
+			combine := func(left, right *RecipeTree) bool {
+				return yield(&RecipeTree{
+					ID:          element.ID,
+					Element:     element.Name,
+					Ingredients: []*RecipeTree{left, right},
+				})
+			}
+			// Without a tree within reach on both sides, enumerating either side is wasted work
+			if d.reaches(recipe[0], limit-1) && d.reaches(recipe[1], limit-1) {
+				for left := range d.exact(recipe[0], limit-1, level+1, leftNeed) {
+					for right := range d.atMost(recipe[1], limit-1, level+1, lacking(left, rest, nodeName)) {
+						if !combine(left, right) {
+							return
+						}
+					}
+				}
+			}
+			if d.reaches(recipe[0], limit-2) && d.reaches(recipe[1], limit-1) {
+				for left := range d.atMost(recipe[0], limit-2, level+1, leftNeed) {
+					for right := range d.exact(recipe[1], limit-1, level+1, lacking(left, rest, nodeName)) {
+						if !combine(left, right) {
+							return
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// The tier of every element as it follows from the usable recipes: 0 for base elements,
+// otherwise one more than the lower tier pair it can be crafted from. Every tree is at least
+// this deep. The scraped tier is not used directly since the pruning rule only guarantees it
+// as an upper bound. Elements missing from the map cannot be crafted
+func recipeTiers(graph *search.RecipeGraph, constraints Constraints) map[*search.ElementNode]int {
+	return search.TierFold[int]{
+		Skip: func(element *search.ElementNode) bool { return slices.Contains(constraints.Exclude, element) },
+		Base: func(*search.ElementNode) int { return 0 },
+		Recipe: func(_ *search.ElementNode, _ []*search.ElementNode, left, right int) int {
+			return 1 + max(left, right)
+		},
+		Better: func(tier, current int) bool { return tier < current },
+	}.Run(graph)
+}
+
+// Whether element has a tree no deeper than limit
+func (d *deepening) reaches(element *search.ElementNode, limit int) bool {
+	depth, craftable := d.depths[element]
+	return craftable && depth <= limit
+}
+
+// Trees of element whose depth is at most limit, shallowest first
+func (d *deepening) atMost(element *search.ElementNode, limit int, level int, need []*search.ElementNode) iter.Seq[*RecipeTree] {
+	return func(yield func(*RecipeTree) bool) {
+		for depth := d.depths[element]; depth <= limit; depth++ {
+			for tree := range d.exact(element, depth, level, need) {
+				if !yield(tree) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package algorithm
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Every element of a dataset the size of the scraped one, within a time budget. Without
+// pruning by the shallowest tree, a single search here used to run for minutes
+func TestIDDFSFinishesInBoundedTime(t *testing.T) {
+	graphs := testGraphs(t)
+	for seed := range uint64(3) {
+		graphs[fmt.Sprintf("synthetic-%d", seed)] = syntheticGraph(t, seed)
+	}
+
+	for name, graph := range graphs {
+		depths := recipeTiers(graph, Constraints{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for _, element := range graph.Elements[1:] {
+				for _, maxPaths := range []int{1, 10} {
+					result, err := Run("iddfs", SearchRequest{Target: element, Graph: graph, MaxPaths: maxPaths})
+					if err != nil {
+						t.Errorf("%s/%s: %v", name, element.Name, err)
+						return
+					}
+					depth, craftable := depths[element]
+					if craftable != (len(result.Trees) > 0) {
+						t.Errorf("%s/%s: %d trees, craftable %v", name, element.Name, len(result.Trees), craftable)
+						continue
+					}
+					for i, tree := range result.Trees {
+						if tree.Depth() < depth {
+							t.Errorf("%s/%s: tree %d is %d deep, shallower than %d", name, element.Name, i, tree.Depth(), depth)
+						}
+						depth = tree.Depth()
+					}
+					if craftable && result.Trees[0].Depth() != depths[element] {
+						t.Errorf("%s/%s: first tree is %d deep, shallowest is %d", name, element.Name, result.Trees[0].Depth(), depths[element])
+					}
+				}
+			}
+		}()
+		select {
+		case <-done:
+		case <-time.After(30 * time.Second):
+			t.Fatalf("%s: iddfs did not search every element within 30s", name)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package algorithm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// One combination the player makes
+type Instruction struct {
+	Step        int       `json:"step"`
+	Ingredients [2]string `json:"ingredients"`
+	Result      string    `json:"result"`
+}
+
+// Instructions lists the combinations of the tree so every ingredient is made before it is used.
+// An element is only crafted once: later uses of the same intermediate reuse it, and the
+// steps below them, which may follow another recipe, are left out with it.
+// Every algorithm answers with RecipeTree, the legacy BFS and DFS shapes are derived from it,
+// so this covers the results of all of them
+func (tree *RecipeTree) Instructions() []Instruction {
+	instructions := make([]Instruction, 0)
+	crafted := make(map[string]bool)
+	var craft func(node *RecipeTree)
+	craft = func(node *RecipeTree) {
+		if node.IsLeaf() || crafted[node.Element] {
+			return
+		}
+		for _, ingredient := range node.Ingredients {
+			craft(ingredient)
+		}
+		crafted[node.Element] = true
+		instructions = append(instructions, Instruction{
+			Step:        len(instructions) + 1,
+			Ingredients: [2]string{node.Ingredients[0].Element, node.Ingredients[1].Element},
+			Result:      node.Element,
+		})
+	}
+	craft(tree)
+	return instructions
+}
+
+// Numbered "Fire + Earth = Lava" lines, one block per tree
+func RenderText(trees []*RecipeTree) string {
+	var b strings.Builder
+	for i, tree := range trees {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		instructions := tree.Instructions()
+		if len(trees) > 1 {
+			fmt.Fprintf(&b, "Recipe %d for %s (%d steps)\n", i+1, tree.Element, len(instructions))
+		}
+		if len(instructions) == 0 {
+			fmt.Fprintf(&b, "%s is a base element, nothing to craft\n", tree.Element)
+		}
+		for _, step := range instructions {
+			fmt.Fprintf(&b, "%d. %s + %s = %s\n", step.Step, step.Ingredients[0], step.Ingredients[1], step.Result)
+		}
+	}
+	return b.String()
+}
+
+// Same as RenderText as a Markdown list with a heading per tree
+func RenderMarkdown(trees []*RecipeTree) string {
+	var b strings.Builder
+	for i, tree := range trees {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		instructions := tree.Instructions()
+		if len(trees) > 1 {
+			fmt.Fprintf(&b, "### Recipe %d for %s\n\n", i+1, tree.Element)
+		}
+		if len(instructions) == 0 {
+			fmt.Fprintf(&b, "**%s** is a base element, nothing to craft.\n", tree.Element)
+		}
+		for _, step := range instructions {
+			fmt.Fprintf(&b, "%d. **%s** + **%s** = **%s**\n", step.Step, step.Ingredients[0], step.Ingredients[1], step.Result)
+		}
+	}
+	return b.String()
+}
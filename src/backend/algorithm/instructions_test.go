@@ -0,0 +1,76 @@
+package algorithm
+
+import (
+	"slices"
+	"testing"
+)
+
+func leaf(element string) *RecipeTree { return &RecipeTree{Element: element} }
+
+func craft(element string, left, right *RecipeTree) *RecipeTree {
+	return &RecipeTree{Element: element, Ingredients: []*RecipeTree{left, right}}
+}
+
+// Rain made from two Clouds, the second one through Pressure
+func reusingTree() *RecipeTree {
+	steam := func() *RecipeTree { return craft("Steam", leaf("Fire"), leaf("Water")) }
+	return craft("Rain",
+		craft("Cloud", steam(), leaf("Air")),
+		craft("Cloud", steam(), craft("Pressure", leaf("Air"), leaf("Air"))),
+	)
+}
+
+func TestInstructionsCraftEveryElementOnce(t *testing.T) {
+	want := []Instruction{
+		{Step: 1, Ingredients: [2]string{"Fire", "Water"}, Result: "Steam"},
+		{Step: 2, Ingredients: [2]string{"Steam", "Air"}, Result: "Cloud"},
+		{Step: 3, Ingredients: [2]string{"Cloud", "Cloud"}, Result: "Rain"},
+	}
+	if got := reusingTree().Instructions(); !slices.Equal(got, want) {
+		t.Errorf("got  %v\nwant %v", got, want)
+	}
+	if got := leaf("Fire").Instructions(); len(got) != 0 {
+		t.Errorf("a base element has steps %v", got)
+	}
+}
+
+func TestRenderInstructions(t *testing.T) {
+	trees := []*RecipeTree{reusingTree(), leaf("Fire")}
+
+	tests := []struct {
+		name   string
+		render func([]*RecipeTree) string
+		trees  []*RecipeTree
+		want   string
+	}{
+		{"text", RenderText, trees[:1], "" +
+			"1. Fire + Water = Steam\n" +
+			"2. Steam + Air = Cloud\n" +
+			"3. Cloud + Cloud = Rain\n"},
+		{"text of several trees", RenderText, trees, "" +
+			"Recipe 1 for Rain (3 steps)\n" +
+			"1. Fire + Water = Steam\n" +
+			"2. Steam + Air = Cloud\n" +
+			"3. Cloud + Cloud = Rain\n" +
+			"\n" +
+			"Recipe 2 for Fire (0 steps)\n" +
+			"Fire is a base element, nothing to craft\n"},
+		{"markdown", RenderMarkdown, trees[:1], "" +
+			"1. **Fire** + **Water** = **Steam**\n" +
+			"2. **Steam** + **Air** = **Cloud**\n" +
+			"3. **Cloud** + **Cloud** = **Rain**\n"},
+		{"markdown of several trees", RenderMarkdown, trees, "" +
+			"### Recipe 1 for Rain\n\n" +
+			"1. **Fire** + **Water** = **Steam**\n" +
+			"2. **Steam** + **Air** = **Cloud**\n" +
+			"3. **Cloud** + **Cloud** = **Rain**\n" +
+			"\n" +
+			"### Recipe 2 for Fire\n\n" +
+			"**Fire** is a base element, nothing to craft.\n"},
+	}
+	for _, test := range tests {
+		if got := test.render(test.trees); got != test.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package algorithm
+
+import "backend/search"
+
+// Smallest recipe tree (fewest combinations) of every element, built on demand
+// from the recipes search.MinimalRecipes picks
+type minimalTrees struct {
+	recipes map[*search.ElementNode]search.MinimalRecipe
+	trees   map[*search.ElementNode]*RecipeTree
+}
+
+func newMinimalTrees(graph *search.RecipeGraph) *minimalTrees {
+	return &minimalTrees{
+		recipes: search.MinimalRecipes(graph),
+		trees:   make(map[*search.ElementNode]*RecipeTree),
+	}
+}
+
+// Smallest tree for element, nil when it cannot be crafted from the base elements
+func (m *minimalTrees) of(element *search.ElementNode) *RecipeTree {
+	if tree, ok := m.trees[element]; ok {
+		return tree
+	}
+	minimal, ok := m.recipes[element]
+	if !ok {
+		return nil
+	}
+	tree := &RecipeTree{ID: element.ID, Element: element.Name}
+	if minimal.Recipe != nil {
+		tree.Ingredients = []*RecipeTree{m.of(minimal.Recipe[0]), m.of(minimal.Recipe[1])}
+	}
+	m.trees[element] = tree
+	return tree
+}
+
+// Number of combinations in the smallest tree, -1 when element cannot be crafted
+func (m *minimalTrees) size(element *search.ElementNode) int {
+	minimal, ok := m.recipes[element]
+	if !ok {
+		return -1
+	}
+	return minimal.Size
+}
+
+// SmallestTrees is a smallest recipe tree of every craftable element, found with one pass over
+// the tiers instead of a search per element. Ties go to the first recipe in graph order, so a
+// tree may differ from the first astar tree while having as many combinations.
+// The trees share their subtrees
+func SmallestTrees(graph *search.RecipeGraph) map[*search.ElementNode]*RecipeTree {
+	minimal := newMinimalTrees(graph)
+	trees := make(map[*search.ElementNode]*RecipeTree, len(minimal.recipes))
+	for element := range minimal.recipes {
+		trees[element] = minimal.of(element)
+	}
+	return trees
+}
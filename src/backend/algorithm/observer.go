@@ -0,0 +1,70 @@
+package algorithm
+
+import (
+	"backend/search"
+	"sync"
+)
+
+type EventType string
+
+const (
+	EventNodeExpanded   EventType = "node_expanded"   // An element's recipe list is about to be examined
+	EventRecipeAccepted EventType = "recipe_accepted" // A recipe was taken into the search result
+	EventRecipePruned   EventType = "recipe_pruned"   // A recipe was rejected, see Reason
+	EventPathCompleted  EventType = "path_completed"  // A complete recipe tree for the target was found
+)
+
+// Reasons a recipe gets pruned
+const (
+	PruneTier        = "tier"        // An ingredient is not of a lower tier than the result
+	PruneDuplicate   = "duplicate"   // The same combination was already taken for this ancestry
+	PruneUncraftable = "uncraftable" // An ingredient has no recipe at all
+	PruneDeadEnd     = "dead_end"    // An ingredient could not be crafted, the DFS backtracks
+	PruneExcluded    = "excluded"    // An ingredient is excluded by the request's constraints
+	PruneIncluded    = "included"    // No tree through the recipe can contain every included element
+)
+
+// One step of a running search, used to animate how the algorithms explore the graph
+type SearchEvent struct {
+	Type        EventType   `json:"type"`
+	Element     string      `json:"element"`
+	Ingredients []string    `json:"ingredients,omitempty"`
+	Depth       int         `json:"depth"` // Distance from the target, negative when counted from a bidirectional search's source
+	Reason      string      `json:"reason,omitempty"`
+	Tree        *RecipeTree `json:"tree,omitempty"` // Only for EventPathCompleted
+}
+
+// Receives search events. Run serializes calls, so implementations need no locking of their own
+type Observer interface {
+	Observe(event SearchEvent)
+}
+
+type ObserverFunc func(event SearchEvent)
+
+func (f ObserverFunc) Observe(event SearchEvent) { f(event) }
+
+type syncObserver struct {
+	mu       sync.Mutex
+	observer Observer
+}
+
+func (o *syncObserver) Observe(event SearchEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.observer.Observe(event)
+}
+
+// Nil safe notify, algorithms call this instead of the observer directly
+func notify(observer Observer, event SearchEvent) {
+	if observer != nil {
+		observer.Observe(event)
+	}
+}
+
+func recipeNames(recipe []*search.ElementNode) []string {
+	names := make([]string, len(recipe))
+	for i, ingredient := range recipe {
+		names[i] = ingredient.Name
+	}
+	return names
+}
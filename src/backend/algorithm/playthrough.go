@@ -0,0 +1,164 @@
+package algorithm
+
+import (
+	"backend/search"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// One combination of a playthrough
+type PlaythroughStep struct {
+	Index       int       `json:"index"`
+	Ingredients [2]string `json:"ingredients"`
+	Creates     []string  `json:"creates"` // Elements discovered by this combination, at least one
+}
+
+// Ordered combinations that discover every element reachable from a starting set
+type Playthrough struct {
+	Start       Checkpoint        `json:"start"`
+	Steps       []PlaythroughStep `json:"steps"`
+	Unreachable []string          `json:"unreachable"` // Elements no combination can ever create
+}
+
+// Bump when the meaning of a checkpoint changes
+const CheckpointVersion = 1
+
+// Progress through a playthrough. Plans are deterministic, so the set of discovered
+// elements is enough to continue with the same combinations from any point
+type Checkpoint struct {
+	Version    int      `json:"version"`
+	Step       int      `json:"step"`       // Combinations made so far
+	Discovered []string `json:"discovered"` // Sorted element names, base elements included
+}
+
+// Two elements that can be combined, with every element the combination creates
+type combination struct {
+	ingredients [2]*search.ElementNode
+	results     []*search.ElementNode
+}
+
+func combinations(graph *search.RecipeGraph) []*combination {
+	byPair := make(map[[2]int]*combination)
+	pairs := make([]*combination, 0)
+	for _, element := range graph.Elements[1:] {
+		for _, recipe := range element.Recipes {
+			// Base elements list the sentinel as their recipe
+			if len(recipe) != 2 || recipe[0].ID == 0 || recipe[1].ID == 0 {
+				continue
+			}
+			a, b := recipe[0], recipe[1]
+			if a.ID > b.ID {
+				a, b = b, a
+			}
+			key := [2]int{a.ID, b.ID}
+			pair, ok := byPair[key]
+			if !ok {
+				pair = &combination{ingredients: [2]*search.ElementNode{a, b}}
+				byPair[key] = pair
+				pairs = append(pairs, pair)
+			}
+			if !slices.Contains(pair.results, element) {
+				pair.results = append(pair.results, element)
+			}
+		}
+	}
+	return pairs
+}
+
+// PlanPlaythrough orders combinations so every one discovers at least one new element, until
+// nothing new can be made. Every element needs one combination, so the plan is minimal unless some
+// pairs create several elements at once. Those make it a covering problem, and the planner
+// greedily takes the pair that discovers the most, then the one whose discoveries are of the lowest tier.
+// Unlike the tree searches, any recipe counts here, tier pruning is not applied
+func PlanPlaythrough(graph *search.RecipeGraph, start Checkpoint) (Playthrough, error) {
+	// A zero checkpoint starts from the base elements
+	if start.Version != 0 && start.Version != CheckpointVersion {
+		return Playthrough{}, fmt.Errorf("%w: checkpoint version %d, expected %d", ErrInvalidRequest, start.Version, CheckpointVersion)
+	}
+	discovered := make(map[*search.ElementNode]bool)
+	for _, base := range graph.BaseElements {
+		discovered[base] = true
+	}
+	for _, name := range start.Discovered {
+		element, err := search.GetElementByName(graph, name)
+		if err != nil {
+			return Playthrough{}, fmt.Errorf("%w: checkpoint element %s", ErrInvalidRequest, name)
+		}
+		discovered[element] = true
+	}
+
+	plan := Playthrough{Steps: make([]PlaythroughStep, 0)}
+	plan.Start = Checkpoint{Version: CheckpointVersion, Step: start.Step, Discovered: discoveredNames(discovered)}
+
+	pairs := combinations(graph)
+	for {
+		var best *combination
+		bestNew, bestTier := 0, 0
+		for _, pair := range pairs {
+			if !discovered[pair.ingredients[0]] || !discovered[pair.ingredients[1]] {
+				continue
+			}
+			fresh, tier := 0, 0
+			for _, result := range pair.results {
+				if !discovered[result] {
+					if fresh == 0 || result.Tier < tier {
+						tier = result.Tier
+					}
+					fresh++
+				}
+			}
+			if fresh == 0 {
+				continue
+			}
+			if best == nil || fresh > bestNew || (fresh == bestNew && tier < bestTier) {
+				best, bestNew, bestTier = pair, fresh, tier
+			}
+		}
+		if best == nil {
+			break
+		}
+
+		step := PlaythroughStep{
+			Index:       start.Step + len(plan.Steps),
+			Ingredients: [2]string{best.ingredients[0].Name, best.ingredients[1].Name},
+			Creates:     make([]string, 0, bestNew),
+		}
+		for _, result := range best.results {
+			if !discovered[result] {
+				discovered[result] = true
+				step.Creates = append(step.Creates, result.Name)
+			}
+		}
+		plan.Steps = append(plan.Steps, step)
+	}
+
+	plan.Unreachable = make([]string, 0)
+	for _, element := range graph.Elements[1:] {
+		if !discovered[element] {
+			plan.Unreachable = append(plan.Unreachable, element.Name)
+		}
+	}
+	sort.Strings(plan.Unreachable)
+	return plan, nil
+}
+
+// Checkpoint after the first done steps of the plan
+func (plan Playthrough) CheckpointAt(done int) Checkpoint {
+	done = max(0, min(done, len(plan.Steps)))
+	discovered := slices.Clone(plan.Start.Discovered)
+	for _, step := range plan.Steps[:done] {
+		discovered = append(discovered, step.Creates...)
+	}
+	sort.Strings(discovered)
+	return Checkpoint{Version: CheckpointVersion, Step: plan.Start.Step + done, Discovered: discovered}
+}
+
+func discoveredNames(discovered map[*search.ElementNode]bool) []string {
+	names := make([]string, 0, len(discovered))
+	for element := range discovered {
+		names = append(names, element.Name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,31 @@
+package algorithm
+
+import (
+	"slices"
+	"testing"
+)
+
+// Every step discovers something new, and resuming from any checkpoint continues with the same steps
+func TestPlaythroughResumesFromAnyCheckpoint(t *testing.T) {
+	for name, graph := range testGraphs(t) {
+		plan, err := PlanPlaythrough(graph, Checkpoint{})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got, want := len(plan.Steps)+len(plan.Unreachable)+len(graph.BaseElements), len(graph.Elements)-1; got > want {
+			t.Errorf("%s: %d steps for %d elements", name, len(plan.Steps), want)
+		}
+
+		for done := 0; done <= len(plan.Steps); done++ {
+			resumed, err := PlanPlaythrough(graph, plan.CheckpointAt(done))
+			if err != nil {
+				t.Fatalf("%s: resuming after %d steps: %v", name, done, err)
+			}
+			if !slices.EqualFunc(resumed.Steps, plan.Steps[done:], func(a, b PlaythroughStep) bool {
+				return a.Index == b.Index && a.Ingredients == b.Ingredients && slices.Equal(a.Creates, b.Creates)
+			}) {
+				t.Fatalf("%s: resuming after %d steps changed the plan", name, done)
+			}
+		}
+	}
+}
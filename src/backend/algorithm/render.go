@@ -0,0 +1,189 @@
+package algorithm
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Text formats recipe trees can be rendered to besides JSON
+var RenderFormats = []string{"mermaid", "dot", "svg", "text", "md"}
+
+// Render writes every tree in one document of the given format, one section per tree.
+// In the drawings ingredients point to what they make and base elements are rounded
+func Render(format string, trees []*RecipeTree) (string, error) {
+	switch strings.ToLower(format) {
+	case "mermaid":
+		return RenderMermaid(trees), nil
+	case "dot":
+		return RenderDOT(trees), nil
+	case "svg":
+		return RenderSVG(trees), nil
+	case "text":
+		return RenderText(trees), nil
+	case "md":
+		return RenderMarkdown(trees), nil
+	}
+	return "", fmt.Errorf("%w: unknown format %s, use one of %s", ErrInvalidRequest, format, strings.Join(RenderFormats, ", "))
+}
+
+// Calls visit for every combination with a unique id per node, elements repeat across a tree
+func walkNumbered(tree *RecipeTree, next *int, visit func(id int, node *RecipeTree, ingredientIDs []int)) int {
+	*next++
+	id := *next
+	ingredientIDs := make([]int, 0, len(tree.Ingredients))
+	for _, ingredient := range tree.Ingredients {
+		ingredientIDs = append(ingredientIDs, walkNumbered(ingredient, next, visit))
+	}
+	visit(id, tree, ingredientIDs)
+	return id
+}
+
+// Mermaid labels are HTML, so markup characters are written as its #name; entity codes
+var mermaidEscaper = strings.NewReplacer(`#`, "#35;", `"`, "#quot;", `<`, "#lt;", `>`, "#gt;", `&`, "#amp;")
+
+func RenderMermaid(trees []*RecipeTree) string {
+	var b strings.Builder
+	b.WriteString("flowchart BT\n")
+	next := 0
+	for i, tree := range trees {
+		fmt.Fprintf(&b, "  subgraph tree%d [\"Tree %d\"]\n", i+1, i+1)
+		walkNumbered(tree, &next, func(id int, node *RecipeTree, ingredientIDs []int) {
+			label := mermaidEscaper.Replace(node.Element)
+			if node.IsLeaf() {
+				fmt.Fprintf(&b, "    n%d([\"%s\"])\n", id, label)
+				return
+			}
+			fmt.Fprintf(&b, "    n%d[\"%s\"]\n", id, label)
+			for _, ingredient := range ingredientIDs {
+				fmt.Fprintf(&b, "    n%d --> n%d\n", ingredient, id)
+			}
+		})
+		b.WriteString("  end\n")
+	}
+	return b.String()
+}
+
+func RenderDOT(trees []*RecipeTree) string {
+	var b strings.Builder
+	b.WriteString("digraph recipe {\n")
+	b.WriteString("  rankdir=BT;\n")
+	b.WriteString("  node [shape=box];\n")
+	next := 0
+	for i, tree := range trees {
+		fmt.Fprintf(&b, "  subgraph cluster_%d {\n", i+1)
+		fmt.Fprintf(&b, "    label=\"Tree %d\";\n", i+1)
+		walkNumbered(tree, &next, func(id int, node *RecipeTree, ingredientIDs []int) {
+			label := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(node.Element)
+			if node.IsLeaf() {
+				fmt.Fprintf(&b, "    n%d [label=\"%s\", style=rounded];\n", id, label)
+				return
+			}
+			fmt.Fprintf(&b, "    n%d [label=\"%s\"];\n", id, label)
+			for _, ingredient := range ingredientIDs {
+				fmt.Fprintf(&b, "    n%d -> n%d;\n", ingredient, id)
+			}
+		})
+		b.WriteString("  }\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+/* ----------------------------------------- SVG ----------------------------------------------- */
+
+// Layout constants in pixels. Text width is estimated, no font metrics are available
+const (
+	svgCharWidth  = 7
+	svgBoxPadding = 16
+	svgBoxHeight  = 28
+	svgRowHeight  = 64
+	svgGap        = 12
+	svgMargin     = 16
+	svgTitle      = 24
+)
+
+// A node placed by the tree layout, x is the center of its box
+type placedNode struct {
+	node     *RecipeTree
+	x, y     float64
+	children []*placedNode
+}
+
+func boxWidth(node *RecipeTree) float64 {
+	return float64(len([]rune(node.Element))*svgCharWidth + svgBoxPadding)
+}
+
+// Horizontal room a subtree needs so no two boxes overlap
+func subtreeWidth(node *RecipeTree, widths map[*RecipeTree]float64) float64 {
+	children := 0.0
+	for i, ingredient := range node.Ingredients {
+		if i > 0 {
+			children += svgGap
+		}
+		children += subtreeWidth(ingredient, widths)
+	}
+	widths[node] = max(boxWidth(node), children)
+	return widths[node]
+}
+
+// Root on top, each subtree centered in the span it was given
+func place(node *RecipeTree, left, y float64, widths map[*RecipeTree]float64) *placedNode {
+	placed := &placedNode{node: node, x: left + widths[node]/2, y: y}
+
+	children := -float64(svgGap)
+	for _, ingredient := range node.Ingredients {
+		children += widths[ingredient] + svgGap
+	}
+	start := left + (widths[node]-children)/2
+	for _, ingredient := range node.Ingredients {
+		placed.children = append(placed.children, place(ingredient, start, y+svgRowHeight, widths))
+		start += widths[ingredient] + svgGap
+	}
+	return placed
+}
+
+// RenderSVG lays the trees out top to bottom in a self-contained SVG document
+func RenderSVG(trees []*RecipeTree) string {
+	var body strings.Builder
+	width, y := 0.0, float64(svgMargin)
+	for i, tree := range trees {
+		widths := make(map[*RecipeTree]float64)
+		subtreeWidth(tree, widths)
+		width = max(width, widths[tree])
+
+		fmt.Fprintf(&body, "  <text x=\"%d\" y=\"%.0f\" class=\"title\">Tree %d</text>\n", svgMargin, y+svgTitle/2, i+1)
+		y += svgTitle
+		root := place(tree, svgMargin, y, widths)
+		writeSVGEdges(&body, root)
+		writeSVGNodes(&body, root)
+		y += float64(tree.Depth()+1)*svgRowHeight - (svgRowHeight - svgBoxHeight) + svgMargin
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%.0f\" height=\"%.0f\" font-family=\"sans-serif\" font-size=\"12\">\n", width+2*svgMargin, y)
+	b.WriteString("  <style>rect{fill:#fff;stroke:#555}rect.base{fill:#e8f1ff;stroke:#4a78c2}line{stroke:#999}text{text-anchor:middle;dominant-baseline:central}text.title{text-anchor:start;font-weight:bold}</style>\n")
+	b.WriteString(body.String())
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+func writeSVGEdges(b *strings.Builder, placed *placedNode) {
+	for _, child := range placed.children {
+		fmt.Fprintf(b, "  <line x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\"/>\n", placed.x, placed.y+svgBoxHeight, child.x, child.y)
+		writeSVGEdges(b, child)
+	}
+}
+
+func writeSVGNodes(b *strings.Builder, placed *placedNode) {
+	w := boxWidth(placed.node)
+	class := ""
+	if placed.node.IsLeaf() {
+		class = ` class="base"`
+	}
+	fmt.Fprintf(b, "  <rect%s x=\"%.1f\" y=\"%.1f\" width=\"%.0f\" height=\"%d\" rx=\"4\"/>\n", class, placed.x-w/2, placed.y, w, svgBoxHeight)
+	fmt.Fprintf(b, "  <text x=\"%.1f\" y=\"%.1f\">%s</text>\n", placed.x, placed.y+svgBoxHeight/2, html.EscapeString(placed.node.Element))
+	for _, child := range placed.children {
+		writeSVGNodes(b, child)
+	}
+}
@@ -0,0 +1,87 @@
+package algorithm
+
+import "testing"
+
+// Names with quotes, spaces, backslashes and markup characters, and a base element on its own
+func renderTrees() []*RecipeTree {
+	return []*RecipeTree{
+		craft(`Rock "n" Roll`, leaf("Salt & Pepper"), craft("<Void>", leaf(`Back\slash`), leaf("Air"))),
+		leaf("Fire"),
+	}
+}
+
+func TestRenderDrawings(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"mermaid", `flowchart BT
+  subgraph tree1 ["Tree 1"]
+    n2(["Salt #amp; Pepper"])
+    n4(["Back\slash"])
+    n5(["Air"])
+    n3["#lt;Void#gt;"]
+    n4 --> n3
+    n5 --> n3
+    n1["Rock #quot;n#quot; Roll"]
+    n2 --> n1
+    n3 --> n1
+  end
+  subgraph tree2 ["Tree 2"]
+    n6(["Fire"])
+  end
+`},
+		{"dot", `digraph recipe {
+  rankdir=BT;
+  node [shape=box];
+  subgraph cluster_1 {
+    label="Tree 1";
+    n2 [label="Salt & Pepper", style=rounded];
+    n4 [label="Back\\slash", style=rounded];
+    n5 [label="Air", style=rounded];
+    n3 [label="<Void>"];
+    n4 -> n3;
+    n5 -> n3;
+    n1 [label="Rock \"n\" Roll"];
+    n2 -> n1;
+    n3 -> n1;
+  }
+  subgraph cluster_2 {
+    label="Tree 2";
+    n6 [label="Fire", style=rounded];
+  }
+}
+`},
+		{"svg", `<svg xmlns="http://www.w3.org/2000/svg" width="286" height="280" font-family="sans-serif" font-size="12">
+  <style>rect{fill:#fff;stroke:#555}rect.base{fill:#e8f1ff;stroke:#4a78c2}line{stroke:#999}text{text-anchor:middle;dominant-baseline:central}text.title{text-anchor:start;font-weight:bold}</style>
+  <text x="16" y="28" class="title">Tree 1</text>
+  <line x1="143.0" y1="68.0" x2="69.5" y2="104.0"/>
+  <line x1="143.0" y1="68.0" x2="202.5" y2="104.0"/>
+  <line x1="202.5" y1="132.0" x2="178.0" y2="168.0"/>
+  <line x1="202.5" y1="132.0" x2="251.5" y2="168.0"/>
+  <rect x="89.5" y="40.0" width="107" height="28" rx="4"/>
+  <text x="143.0" y="54.0">Rock &#34;n&#34; Roll</text>
+  <rect class="base" x="16.0" y="104.0" width="107" height="28" rx="4"/>
+  <text x="69.5" y="118.0">Salt &amp; Pepper</text>
+  <rect x="173.5" y="104.0" width="58" height="28" rx="4"/>
+  <text x="202.5" y="118.0">&lt;Void&gt;</text>
+  <rect class="base" x="135.0" y="168.0" width="86" height="28" rx="4"/>
+  <text x="178.0" y="182.0">Back\slash</text>
+  <rect class="base" x="233.0" y="168.0" width="37" height="28" rx="4"/>
+  <text x="251.5" y="182.0">Air</text>
+  <text x="16" y="224" class="title">Tree 2</text>
+  <rect class="base" x="16.0" y="236.0" width="44" height="28" rx="4"/>
+  <text x="38.0" y="250.0">Fire</text>
+</svg>
+`},
+	}
+	for _, test := range tests {
+		got, err := Render(test.format, renderTrees())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.format, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package algorithm
+
+import (
+	"backend/search"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// What every algorithm receives from its caller
+type SearchRequest struct {
+	Target   *search.ElementNode
+	Graph    *search.RecipeGraph
+	MaxPaths int // Number of recipe trees wanted, at least 1
+	// Element the tree must be crafted through, required by bidirectional and ignored by the others
+	Source *search.ElementNode
+	// Elements every tree must or must not contain
+	Constraints Constraints
+
+	// Optional. Called with every tree as soon as it is found, returning false stops the search early
+	Emit func(tree *RecipeTree) bool
+	// Optional. Receives every step of the search as it happens
+	Observer Observer
+}
+
+func (req SearchRequest) emit(tree *RecipeTree) bool {
+	if req.Emit == nil {
+		return true
+	}
+	return req.Emit(tree)
+}
+
+// What every algorithm hands back to its caller
+type SearchResult struct {
+	Algo    string
+	Element string
+	Trees   []*RecipeTree // In discovery order
+	Chain   []string      // Source to target crafting chain, bidirectional only
+	Stats   SearchStats
+}
+
+// Trees in the shape this algorithm returned before results were unified
+func (result SearchResult) LegacyPaths() []any {
+	return LegacyPaths(result.Algo, result.Trees)
+}
+
+// A recipe search algorithm that can be dispatched by name
+type Searcher interface {
+	Search(req SearchRequest) (SearchResult, error)
+}
+
+var ErrUnknownAlgorithm = errors.New("unknown algorithm")
+var ErrInvalidRequest = errors.New("invalid search request")
+
+var registryMutex = sync.RWMutex{}
+var registry = make(map[string]Searcher)
+
+// Register makes a searcher available under the given name.
+// Names are case insensitive, registering the same name twice panics.
+func Register(name string, searcher Searcher) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	name = strings.ToLower(name)
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("algorithm %q registered twice", name))
+	}
+	registry[name] = searcher
+}
+
+func Lookup(name string) (Searcher, error) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	searcher, ok := registry[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAlgorithm, name)
+	}
+	return searcher, nil
+}
+
+// Sorted names of all registered algorithms
+func Algorithms() []string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run looks up the algorithm by name and runs the request through it
+func Run(name string, req SearchRequest) (SearchResult, error) {
+	searcher, err := Lookup(name)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	if req.Target == nil {
+		return SearchResult{}, fmt.Errorf("%w: no target", ErrInvalidRequest)
+	}
+	if req.MaxPaths <= 0 {
+		req.MaxPaths = 1
+	}
+	if req.Observer != nil {
+		req.Observer = &syncObserver{observer: req.Observer}
+	}
+	if !req.Constraints.IsEmpty() {
+		if req.Graph == nil {
+			return SearchResult{}, fmt.Errorf("%w: constraints need the recipe graph", ErrInvalidRequest)
+		}
+		if err := CheckConstraints(req.Target, req.Graph, req.Constraints); err != nil {
+			return SearchResult{}, err
+		}
+	}
+
+	start := time.Now()
+	result, err := searcher.Search(req)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	if len(result.Trees) == 0 && result.Stats.LimitHit {
+		if len(req.Constraints.Include) > 0 {
+			return SearchResult{}, fmt.Errorf("%w: gave up before finding a tree of %s with %s",
+				ErrSearchLimit, req.Target.Name, strings.Join(names(req.Constraints.Include), ", "))
+		}
+		return SearchResult{}, fmt.Errorf("%w: gave up before finding a tree of %s", ErrSearchLimit, req.Target.Name)
+	}
+	if len(result.Trees) == 0 && len(req.Constraints.Include) > 0 {
+		// Each included element is reachable on its own, but no tree was found holding all of them
+		return SearchResult{}, &ConstraintError{
+			Target:   req.Target.Name,
+			Reason:   "no recipe tree contains every included element",
+			Blocking: names(req.Constraints.Include),
+		}
+	}
+	result.Stats.WallTimeMs = elapsedMs(start)
+	result.Algo = strings.ToLower(name)
+	result.Element = req.Target.Name
+	return result, nil
+}
+
+func init() {
+	Register("astar", astarSearcher{})
+	Register("bfs", bfsSearcher{})
+	Register("dfs", dfsSearcher{})
+	Register("iddfs", iddfsSearcher{})
+	Register("bidirectional", bidirectionalSearcher{})
+}
+
+/* ----------------------------------------- Built-in Searchers ----------------------------------------------- */
+
+// ReverseBFS shares its combination cache between calls, so only one search may run at a time
+var bfsMutex = sync.Mutex{}
+
+type bfsSearcher struct{}
+
+func (bfsSearcher) Search(req SearchRequest) (SearchResult, error) {
+	bfsMutex.Lock()
+	defer bfsMutex.Unlock()
+
+	ResetCaches()
+	big, stats := ReverseBFS(req.Target, 1, req.Observer)
+	if big == nil {
+		// Base elements are their own recipe tree
+		leaf := &RecipeTree{ID: req.Target.ID, Element: req.Target.Name}
+		notify(req.Observer, SearchEvent{Type: EventPathCompleted, Element: leaf.Element, Tree: leaf})
+		req.emit(leaf)
+		return SearchResult{Trees: []*RecipeTree{leaf}, Stats: stats}, nil
+	}
+	if !slices.ContainsFunc(big.Recipes, func(recipe JSONRecipe) bool { return recipe.Result == req.Target.Name }) {
+		// Every recipe of target was pruned by tier, a lone leaf would pass it off as a base element
+		return SearchResult{Trees: make([]*RecipeTree, 0), Stats: stats}, nil
+	}
+
+	// Trees are generated lazily, so each one is handed out before the next is built
+	trees := collectTrees(req, IterTreesWithout(*big, req.Target.Name, req.Constraints), &stats)
+	return SearchResult{Trees: trees, Stats: stats}, nil
+}
+
+type dfsSearcher struct{}
+
+func (dfsSearcher) Search(req SearchRequest) (SearchResult, error) {
+	if req.Graph == nil {
+		return SearchResult{}, fmt.Errorf("%w: dfs needs the recipe graph", ErrInvalidRequest)
+	}
+
+	var stats SearchStats
+	trees := DFS(req, &stats)
+	return SearchResult{Trees: trees, Stats: stats}, nil
+}
+
+type iddfsSearcher struct{}
+
+func (iddfsSearcher) Search(req SearchRequest) (SearchResult, error) {
+	if req.Graph == nil {
+		return SearchResult{}, fmt.Errorf("%w: iddfs needs the recipe graph", ErrInvalidRequest)
+	}
+
+	var stats SearchStats
+	trees := collectTrees(req, IDDFS(req, &stats), &stats)
+	return SearchResult{Trees: trees, Stats: stats}, nil
+}
+
+type astarSearcher struct{}
+
+func (astarSearcher) Search(req SearchRequest) (SearchResult, error) {
+	if req.Graph == nil {
+		return SearchResult{}, fmt.Errorf("%w: astar needs the recipe graph", ErrInvalidRequest)
+	}
+
+	var stats SearchStats
+	trees := collectTrees(req, AStar(req, &stats), &stats)
+	return SearchResult{Trees: trees, Stats: stats}, nil
+}
+
+// Takes up to MaxPaths distinct trees that satisfy the constraints, handing each one out as it comes
+func collectTrees(req SearchRequest, found iter.Seq[*RecipeTree], stats *SearchStats) []*RecipeTree {
+	trees := make([]*RecipeTree, 0)
+	distinct := make(treeSet)
+	rejected := 0
+	for tree := range found {
+		if !distinct.Add(tree) {
+			stats.DedupHits++
+			continue
+		}
+		if !req.Constraints.satisfiedBy(tree) {
+			rejected++
+			if rejected > maxConstraintRejects {
+				stats.LimitHit = true
+				break
+			}
+			continue
+		}
+		trees = append(trees, tree)
+		notify(req.Observer, SearchEvent{Type: EventPathCompleted, Element: tree.Element, Tree: tree})
+		if !req.emit(tree) {
+			break
+		}
+		// Stopped by max like DFS, without building another tree just to learn whether one exists
+		if len(trees) == req.MaxPaths {
+			stats.LimitHit = true
+			break
+		}
+	}
+	return trees
+}
+
+type bidirectionalSearcher struct{}
+
+func (bidirectionalSearcher) Search(req SearchRequest) (SearchResult, error) {
+	if req.Graph == nil || req.Source == nil {
+		return SearchResult{}, fmt.Errorf("%w: bidirectional search needs the recipe graph and a source element", ErrInvalidRequest)
+	}
+
+	tree, chain, stats, err := BidirectionalSearch(req.Source, req.Target, req.Graph, req.Observer)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	if violations := req.Constraints.violatedBy(tree); len(violations) > 0 {
+		// The chain is fixed by the shortest path, so constraints only filter the result
+		return SearchResult{}, &ConstraintError{
+			Target:   req.Target.Name,
+			Reason:   "the shortest chain does not satisfy the constraints",
+			Blocking: violations,
+		}
+	}
+	req.emit(tree)
+	return SearchResult{Trees: []*RecipeTree{tree}, Chain: chain, Stats: stats}, nil
+}
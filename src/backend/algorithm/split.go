@@ -1,86 +1,154 @@
 package algorithm
 
+import (
+	"iter"
+	"slices"
+	"sort"
+)
 
-func ExpandPaths(big GraphJSONWithRecipes, target string, maxPaths int) []GraphJSONWithRecipes {
-	byResult := make(map[string][]JSONRecipe)
+// Splits the merged ReverseBFS graph into individual recipe trees
+type treeExpander struct {
+	byResult   map[string][]JSONRecipe
+	nodeByName map[string]JSONNode
+	contains   map[string]map[string]bool // Included element -> element -> whether a tree of it can hold the included one
+}
+
+func newTreeExpander(big GraphJSONWithRecipes) *treeExpander {
+	expander := &treeExpander{
+		byResult:   make(map[string][]JSONRecipe),
+		nodeByName: make(map[string]JSONNode),
+		contains:   make(map[string]map[string]bool),
+	}
+	// ReverseBFS keeps one copy of a combination per step it was reached at, and the
+	// dataset may list both ingredient orders. Either way it is the same recipe here
+	seen := make(map[string]bool)
 	for _, r := range big.Recipes {
-		byResult[r.Result] = append(byResult[r.Result], r)
+		pair := []string{r.Ingredients[0], r.Ingredients[1]}
+		sort.Strings(pair)
+		signature := r.Result + "=" + pair[0] + "+" + pair[1]
+		if seen[signature] {
+			continue
+		}
+		seen[signature] = true
+		expander.byResult[r.Result] = append(expander.byResult[r.Result], r)
 	}
-	nodeByName := make(map[string]JSONNode)
 	for _, n := range big.Nodes {
-		nodeByName[n.Name] = n
+		expander.nodeByName[n.Name] = n
 	}
+	return expander
+}
 
-	type recipePath = []JSONRecipe
-	mem := make(map[string][]recipePath)
-	var out []recipePath
-
-	var dfs func(string) []recipePath
-	dfs = func(elem string) []recipePath {
-    recs := byResult[elem]
-    if len(recs) == 0 {
-        return []recipePath{{}}
-    }
-    if cached, ok := mem[elem]; ok {
-        return cached
-    }
-
-    var paths []recipePath
-    for _, r := range recs {
-        prod := []recipePath{{}}
-        for _, ing := range r.Ingredients {
-            ingPaths := dfs(ing)
-            if maxPaths > 0 && len(out)+len(paths) >= maxPaths {
-                break
-            }
-            var next []recipePath
-            for _, base := range prod {
-                for _, add := range ingPaths {
-                    joined := append(append(recipePath{}, base...), add...)
-                    next = append(next, joined)
-                }
-            }
-            prod = next
-        }
-        for _, p := range prod {
-            paths = append(paths, append(append(recipePath{}, p...), r))
-            if maxPaths > 0 && len(out)+len(paths) >= maxPaths {
-                break
-            }
-        }
-        if maxPaths > 0 && len(out)+len(paths) >= maxPaths {
-            break
-        }
-    }
-    mem[elem] = paths
-    return paths
+// Drops every recipe with an excluded ingredient. Elements left without recipes yield no trees
+func (e *treeExpander) without(constraints Constraints) *treeExpander {
+	for result, recs := range e.byResult {
+		kept := make([]JSONRecipe, 0, len(recs))
+		for _, r := range recs {
+			if !constraints.excludes(r.Ingredients[0]) && !constraints.excludes(r.Ingredients[1]) {
+				kept = append(kept, r)
+			}
+		}
+		e.byResult[result] = kept
 	}
+	return e
+}
 
+// Whether some tree of elem can contain included, remembered per included element
+func (e *treeExpander) canContain(elem, included string) bool {
+	if elem == included {
+		return true
+	}
+	known, ok := e.contains[included]
+	if !ok {
+		known = make(map[string]bool)
+		e.contains[included] = known
+	}
+	if contains, ok := known[elem]; ok {
+		return contains
+	}
+	contains := slices.ContainsFunc(e.byResult[elem], func(r JSONRecipe) bool {
+		return e.canContain(r.Ingredients[0], included) || e.canContain(r.Ingredients[1], included)
+	})
+	known[elem] = contains
+	return contains
+}
 
-	out = dfs(target)
-	if maxPaths > 0 && len(out) > maxPaths {
-		out = out[:maxPaths]
+// Whether every needed element can still end up in a tree of one of elems
+func (e *treeExpander) possible(need []string, elems ...string) bool {
+	for _, included := range need {
+		if !slices.ContainsFunc(elems, func(elem string) bool { return e.canContain(elem, included) }) {
+			return false
+		}
 	}
+	return true
+}
 
-	results := make([]GraphJSONWithRecipes, 0, len(out))
-	for _, recs := range out {
-		seen := map[string]struct{}{target: {}}
+// Every tree for elem as a cartesian product of its ingredients' trees, generated one at a time.
+// Nothing is memoized: sub-iterators are restarted instead, so memory stays proportional to the tree depth.
+// Combinations that cannot hold every needed element are skipped
+func (e *treeExpander) trees(elem string, need []string) iter.Seq[*RecipeTree] {
+	return func(yield func(*RecipeTree) bool) {
+		if !e.possible(need, elem) {
+			return
+		}
+		recs, craftable := e.byResult[elem]
+		if !craftable {
+			yield(&RecipeTree{ID: e.nodeByName[elem].ID, Element: elem})
+			return
+		}
+
+		rest := without(need, elem)
 		for _, r := range recs {
-			seen[r.Result] = struct{}{}
-			for _, ing := range r.Ingredients {
-				seen[ing] = struct{}{}
+			if !e.possible(rest, r.Ingredients...) {
+				continue
 			}
-		}
-		nodes := make([]JSONNode, 0, len(seen))
-		for name := range seen {
-			if n, ok := nodeByName[name]; ok {
-				nodes = append(nodes, n)
+			// What the right side cannot supply has to come from the left
+			leftNeed := slices.DeleteFunc(slices.Clone(rest), func(included string) bool { return e.canContain(r.Ingredients[1], included) })
+			for left := range e.trees(r.Ingredients[0], leftNeed) {
+				for right := range e.trees(r.Ingredients[1], lacking(left, rest, func(included string) string { return included })) {
+					tree := &RecipeTree{
+						ID:          e.nodeByName[elem].ID,
+						Element:     elem,
+						Ingredients: []*RecipeTree{left, right},
+					}
+					if !yield(tree) {
+						return
+					}
+				}
 			}
 		}
-		results = append(results, GraphJSONWithRecipes{
-			Nodes:   nodes,
-			Recipes: recs,
-		})
+	}
+}
+
+// IterTrees lazily yields the recipe trees for target contained in the merged ReverseBFS graph
+func IterTrees(big GraphJSONWithRecipes, target string) iter.Seq[*RecipeTree] {
+	return newTreeExpander(big).trees(target, nil)
+}
+
+// IterTreesWithout is IterTrees skipping every tree that contains an excluded element, and
+// most of those that miss an included one
+func IterTreesWithout(big GraphJSONWithRecipes, target string, constraints Constraints) iter.Seq[*RecipeTree] {
+	return newTreeExpander(big).without(constraints).trees(target, names(constraints.Include))
+}
+
+// ExpandTrees collects at most maxPaths trees from IterTrees, all of them if maxPaths <= 0
+func ExpandTrees(big GraphJSONWithRecipes, target string, maxPaths int) []*RecipeTree {
+	out := make([]*RecipeTree, 0)
+	for tree := range IterTrees(big, target) {
+		out = append(out, tree)
+		if maxPaths > 0 && len(out) >= maxPaths {
+			break
+		}
+	}
+	return out
+}
+
+// ExpandPaths is ExpandTrees in the flat BFS shape
+func ExpandPaths(big GraphJSONWithRecipes, target string, maxPaths int) []GraphJSONWithRecipes {
+	trees := ExpandTrees(big, target, maxPaths)
+
+	results := make([]GraphJSONWithRecipes, 0, len(trees))
+	for _, tree := range trees {
+		results = append(results, tree.ToGraphJSONWithRecipes())
 	}
 	return results
 }
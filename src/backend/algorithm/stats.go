@@ -0,0 +1,34 @@
+package algorithm
+
+import "time"
+
+// Counters every algorithm reports about its own run.
+// An element is expanded when its recipe list is examined, base elements are never expanded.
+type SearchStats struct {
+	WallTimeMs          float64 `json:"wallTimeMs"`
+	NodesExpanded       int     `json:"nodesExpanded"`
+	RecipesConsidered   int     `json:"recipesConsidered"`
+	RecipesPrunedByTier int     `json:"recipesPrunedByTier"`
+	DedupHits           int     `json:"dedupHits"`    // Recipes skipped because an equivalent one was already taken
+	PeakFrontier        int     `json:"peakFrontier"` // Largest BFS queue, or most DFS searches alive at once
+	GoroutinesSpawned   int     `json:"goroutinesSpawned"`
+	LimitHit            bool    `json:"limitHit"` // Stopped by max paths or an iteration cap, not by exhausting the search
+}
+
+func (stats *SearchStats) merge(other SearchStats) {
+	stats.NodesExpanded += other.NodesExpanded
+	stats.RecipesConsidered += other.RecipesConsidered
+	stats.RecipesPrunedByTier += other.RecipesPrunedByTier
+	stats.DedupHits += other.DedupHits
+	stats.PeakFrontier = max(stats.PeakFrontier, other.PeakFrontier)
+	stats.GoroutinesSpawned += other.GoroutinesSpawned
+	stats.LimitHit = stats.LimitHit || other.LimitHit
+}
+
+func (stats *SearchStats) observeFrontier(size int) {
+	stats.PeakFrontier = max(stats.PeakFrontier, size)
+}
+
+func elapsedMs(start time.Time) float64 {
+	return float64(time.Since(start).Microseconds()) / 1000
+}
@@ -0,0 +1,150 @@
+package algorithm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Canonical search result shared by every algorithm.
+// A node is either a base element (no ingredients) or an element crafted from exactly two ingredients.
+// Identical subtrees may be shared between trees, so trees must be treated as read only.
+type RecipeTree struct {
+	ID          int           `json:"id"`
+	Element     string        `json:"element"`
+	Ingredients []*RecipeTree `json:"ingredients,omitempty"`
+}
+
+func (tree *RecipeTree) IsLeaf() bool { return len(tree.Ingredients) == 0 }
+
+// Number of combinations needed to craft the root, counting repeated intermediates every time
+func (tree *RecipeTree) Size() int {
+	if tree.IsLeaf() {
+		return 0
+	}
+	size := 1
+	for _, ingredient := range tree.Ingredients {
+		size += ingredient.Size()
+	}
+	return size
+}
+
+// Longest chain of combinations from a leaf to the root
+func (tree *RecipeTree) Depth() int {
+	depth := 0
+	for _, ingredient := range tree.Ingredients {
+		depth = max(depth, 1+ingredient.Depth())
+	}
+	return depth
+}
+
+func (tree *RecipeTree) String() string {
+	if tree.IsLeaf() {
+		return tree.Element
+	}
+	parts := make([]string, len(tree.Ingredients))
+	for i, ingredient := range tree.Ingredients {
+		parts[i] = ingredient.String()
+	}
+	return fmt.Sprintf("%s(%s)", tree.Element, strings.Join(parts, " + "))
+}
+
+// Visit every node, ingredients before the element they craft
+func (tree *RecipeTree) walkPostOrder(visit func(node *RecipeTree, depth int), depth int) {
+	for _, ingredient := range tree.Ingredients {
+		ingredient.walkPostOrder(visit, depth+1)
+	}
+	visit(tree, depth)
+}
+
+/* ----------------------------------------- Legacy Shapes ----------------------------------------------- */
+
+// Flat BFS shape: every combination with its distance from the target as Step
+func (tree *RecipeTree) ToGraphJSONWithRecipes() GraphJSONWithRecipes {
+	nodes := make([]JSONNode, 0)
+	recipes := make([]JSONRecipe, 0)
+	seenNode := make(map[string]bool)
+	seenRecipe := make(map[string]bool)
+
+	tree.walkPostOrder(func(node *RecipeTree, depth int) {
+		if !seenNode[node.Element] {
+			seenNode[node.Element] = true
+			nodes = append(nodes, JSONNode{ID: node.ID, Name: node.Element})
+		}
+		if node.IsLeaf() {
+			return
+		}
+
+		recipe := JSONRecipe{
+			Ingredients: []string{node.Ingredients[0].Element, node.Ingredients[1].Element},
+			Result:      node.Element,
+			Step:        depth,
+		}
+		recipeSignature := fmt.Sprintf("%s=%s+%s@%d", recipe.Result, recipe.Ingredients[0], recipe.Ingredients[1], recipe.Step)
+		if !seenRecipe[recipeSignature] {
+			seenRecipe[recipeSignature] = true
+			recipes = append(recipes, recipe)
+		}
+	}, 0)
+
+	return GraphJSONWithRecipes{
+		Nodes:   nodes,
+		Recipes: recipes,
+	}
+}
+
+// Indexed DFS shape. Nodes are numbered in post order so the target always has the highest index
+func (tree *RecipeTree) ToPathResult() PathResult {
+	pathJSON := make(PathResult)
+	nextID := 0
+
+	var number func(node *RecipeTree) string
+	number = func(node *RecipeTree) string {
+		recipe := make([]string, 0, len(node.Ingredients))
+		for _, ingredient := range node.Ingredients {
+			recipe = append(recipe, number(ingredient))
+		}
+		id := fmt.Sprintf("%d", nextID)
+		nextID++
+		pathJSON[id] = RecipeJSON{
+			Element: node.Element,
+			Recipe:  recipe,
+		}
+		return id
+	}
+	number(tree)
+
+	return pathJSON
+}
+
+// LegacyPaths converts trees to the shape the algorithm used to return before results were unified:
+// PathResult for dfs, GraphJSONWithRecipes for everything else
+func LegacyPaths(algo string, trees []*RecipeTree) []any {
+	paths := make([]any, 0, len(trees))
+	for _, tree := range trees {
+		if algo == "dfs" {
+			paths = append(paths, tree.ToPathResult())
+		} else {
+			paths = append(paths, tree.ToGraphJSONWithRecipes())
+		}
+	}
+	return paths
+}
+
+/* ----------------------------------------- Tree Construction ----------------------------------------------- */
+
+// Convert a DFS recipe node. Base elements point to themselves as composition, which ends the recursion
+func treeFromRecipe(recipe *Recipe) *RecipeTree {
+	tree := &RecipeTree{
+		ID:      recipe.element.ID,
+		Element: recipe.element.Name,
+	}
+	for _, comp := range recipe.composition {
+		if comp == recipe {
+			return tree
+		}
+	}
+	for _, comp := range recipe.composition {
+		tree.Ingredients = append(tree.Ingredients, treeFromRecipe(comp))
+	}
+	return tree
+}
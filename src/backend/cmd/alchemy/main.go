@@ -0,0 +1,337 @@
+// Command alchemy runs the recipe searches from the terminal and serves the HTTP API.
+//
+//	alchemy search [-algo bfs] [-max 1] [-format text] [-include a,b] [-exclude c] <element>
+//	alchemy scrape [-icons]
+//	alchemy elements [-tier n] [-format text|json]
+//	alchemy export [-format dot|graphml|gexf] [-o file]
+//	alchemy serve [-addr :8080] [-scrape] [-cache 512] [-cache-mb 64] [-index] [-index-file index.json]
+//	alchemy repl
+//
+// Every command takes -dataset, the recipes written by the scraper.
+// Exit codes: 0 success, 1 failure, 2 bad usage, 3 element or recipe not found
+package main
+
+import (
+	"backend/algorithm"
+	"backend/repl"
+	"backend/scraping"
+	"backend/search"
+	"backend/server"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+const (
+	exitFailure  = 1
+	exitUsage    = 2
+	exitNotFound = 3
+)
+
+const defaultDataset = "scraping/recipes.json"
+
+// An error with the exit code it should end the process with
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func usageError(format string, args ...any) error {
+	return &exitError{code: exitUsage, err: fmt.Errorf(format, args...)}
+}
+
+func notFoundError(err error) error {
+	return &exitError{code: exitNotFound, err: err}
+}
+
+var commands = map[string]func(args []string) error{
+	"search":   searchCommand,
+	"scrape":   scrapeCommand,
+	"elements": elementsCommand,
+	"export":   exportCommand,
+	"serve":    serveCommand,
+	"repl":     replCommand,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(exitUsage)
+	}
+	if os.Args[1] == "help" || os.Args[1] == "-h" || os.Args[1] == "--help" {
+		usage(os.Stdout)
+		return
+	}
+
+	command, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "alchemy: unknown command %q\n", os.Args[1])
+		usage(os.Stderr)
+		os.Exit(exitUsage)
+	}
+
+	if err := command(os.Args[2:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintln(os.Stderr, "alchemy:", err)
+		var exit *exitError
+		if errors.As(err, &exit) {
+			os.Exit(exit.code)
+		}
+		os.Exit(exitFailure)
+	}
+}
+
+func usage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	fmt.Fprintf(w, "usage: alchemy <%s> [flags]\n", strings.Join(names, "|"))
+	fmt.Fprintln(w, "run alchemy <command> -h for the flags of a command")
+}
+
+func newFlags(name string) (*flag.FlagSet, *string) {
+	flags := flag.NewFlagSet("alchemy "+name, flag.ContinueOnError)
+	dataset := flags.String("dataset", defaultDataset, "recipes written by the scraper")
+	return flags, dataset
+}
+
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &exitError{code: exitUsage, err: err}
+	}
+	return nil
+}
+
+func loadGraph(dataset string) (*search.RecipeGraph, error) {
+	recipes, err := scraping.ReadRecipesJSON(dataset)
+	if err != nil {
+		return nil, fmt.Errorf("reading dataset, run alchemy scrape first: %w", err)
+	}
+	var graph search.RecipeGraph
+	if err := search.ConstructRecipeGraph(recipes, &graph); err != nil {
+		return nil, err
+	}
+	return &graph, nil
+}
+
+func elementsByName(graph *search.RecipeGraph, list string) ([]*search.ElementNode, error) {
+	elements := make([]*search.ElementNode, 0)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		element, err := search.GetElementByName(graph, name)
+		if err != nil {
+			return nil, notFoundError(err)
+		}
+		elements = append(elements, element)
+	}
+	return elements, nil
+}
+
+/* ----------------------------------------- Commands ----------------------------------------------- */
+
+func searchCommand(args []string) error {
+	flags, dataset := newFlags("search")
+	algo := flags.String("algo", "bfs", "search algorithm, one of "+strings.Join(algorithm.Algorithms(), ", "))
+	maxPaths := flags.Int("max", 1, "number of recipe trees")
+	format := flags.String("format", "text", "output format, json or one of "+strings.Join(algorithm.RenderFormats, ", "))
+	include := flags.String("include", "", "comma separated elements every tree must contain")
+	exclude := flags.String("exclude", "", "comma separated elements no tree may contain")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return usageError("search needs an element, for example alchemy search \"Acid rain\"")
+	}
+	if *maxPaths <= 0 {
+		return usageError("max must be greater than 0")
+	}
+	if *format != "json" && !slices.Contains(algorithm.RenderFormats, *format) {
+		return usageError("format must be json or one of %s", strings.Join(algorithm.RenderFormats, ", "))
+	}
+
+	graph, err := loadGraph(*dataset)
+	if err != nil {
+		return err
+	}
+	// Names may contain spaces, so unquoted words are joined back together
+	target, err := search.GetElementByName(graph, strings.Join(flags.Args(), " "))
+	if err != nil {
+		return notFoundError(err)
+	}
+	var constraints algorithm.Constraints
+	if constraints.Include, err = elementsByName(graph, *include); err != nil {
+		return err
+	}
+	if constraints.Exclude, err = elementsByName(graph, *exclude); err != nil {
+		return err
+	}
+
+	result, err := algorithm.Run(*algo, algorithm.SearchRequest{
+		Target:      target,
+		Graph:       graph,
+		MaxPaths:    *maxPaths,
+		Constraints: constraints,
+	})
+	if errors.Is(err, algorithm.ErrUnknownAlgorithm) {
+		return usageError("algo must be one of %s", strings.Join(algorithm.Algorithms(), ", "))
+	}
+	if errors.Is(err, algorithm.ErrNoRecipe) {
+		return notFoundError(err)
+	}
+	if err != nil {
+		return err
+	}
+	if len(result.Trees) == 0 {
+		return notFoundError(fmt.Errorf("no recipe found for %s", target.Name))
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(server.RecipeResponse(result)) // Same keys as the API answer
+	}
+	rendered, err := algorithm.Render(*format, result.Trees)
+	if err != nil {
+		return err
+	}
+	fmt.Print(rendered)
+	return nil
+}
+
+func scrapeCommand(args []string) error {
+	flags, dataset := newFlags("scrape")
+	icons := flags.Bool("icons", false, "also download element icons")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	return scraping.ScrapeRecipesTo(*dataset, *icons)
+}
+
+func elementsCommand(args []string) error {
+	flags, dataset := newFlags("elements")
+	tier := flags.Int("tier", -1, "only list elements of this tier")
+	format := flags.String("format", "text", "output format, text or json")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *format != "text" && *format != "json" {
+		return usageError("format must be text or json")
+	}
+
+	graph, err := loadGraph(*dataset)
+	if err != nil {
+		return err
+	}
+
+	type element struct {
+		Name string `json:"name"`
+		Tier int    `json:"tier"`
+	}
+	elements := make([]element, 0, len(graph.Elements)-1)
+	for _, node := range graph.Elements[1:] {
+		if *tier < 0 || node.Tier == *tier {
+			elements = append(elements, element{Name: node.Name, Tier: node.Tier})
+		}
+	}
+	if len(elements) == 0 {
+		return notFoundError(fmt.Errorf("no element of tier %d", *tier))
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(elements)
+	}
+	for _, element := range elements {
+		fmt.Printf("%s\t%d\n", element.Name, element.Tier)
+	}
+	return nil
+}
+
+func exportCommand(args []string) error {
+	flags, dataset := newFlags("export")
+	format := flags.String("format", "dot", "output format, one of "+strings.Join(search.ExportFormats, ", "))
+	output := flags.String("o", "", "output file, standard output when empty")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if !slices.Contains(search.ExportFormats, *format) {
+		return usageError("format must be one of %s", strings.Join(search.ExportFormats, ", "))
+	}
+
+	graph, err := loadGraph(*dataset)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	return search.Export(graph, *format, w)
+}
+
+func serveCommand(args []string) error {
+	flags, dataset := newFlags("serve")
+	addr := flags.String("addr", ":8080", "address to listen on")
+	scrape := flags.Bool("scrape", false, "scrape a fresh dataset before serving")
+	cacheEntries := flags.Int("cache", 0, "search results to cache, 0 for the default and -1 to turn caching off")
+	cacheMB := flags.Int64("cache-mb", 0, "memory the cached results may take in MiB, 0 for the default")
+	index := flags.Bool("index", false, "precompute the astar, dfs and bfs recipe of every element before serving")
+	indexFile := flags.String("index-file", "", "reuse the precomputed recipes saved in this file, or save them there (implies -index)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if *scrape {
+		if err := scraping.ScrapeRecipesTo(*dataset, false); err != nil {
+			return err
+		}
+	}
+	graph, err := loadGraph(*dataset)
+	if err != nil {
+		return err
+	}
+	return server.New(graph, server.Config{
+		CacheEntries: *cacheEntries,
+		CacheBytes:   *cacheMB << 20,
+		Index:        *index || *indexFile != "",
+		IndexFile:    *indexFile,
+		Reload:       func() (*search.RecipeGraph, error) { return loadGraph(*dataset) },
+	}).Run(*addr)
+}
+
+func replCommand(args []string) error {
+	flags, dataset := newFlags("repl")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	graph, err := loadGraph(*dataset)
+	if err != nil {
+		return err
+	}
+	return repl.New(graph, os.Stdout).Run(os.Stdin)
+}
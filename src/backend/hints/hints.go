@@ -0,0 +1,132 @@
+package hints
+
+import (
+	"backend/search"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// How much of a suggestion a hint gives away
+type Level int
+
+const (
+	OneIngredient   Level = iota + 1 // Only the first ingredient
+	BothIngredients                  // Both ingredients, not what they make
+	FullRecipe                       // Both ingredients and the result
+)
+
+var levelNames = map[Level]string{
+	OneIngredient:   "ingredient",
+	BothIngredients: "ingredients",
+	FullRecipe:      "result",
+}
+
+func (level Level) String() string { return levelNames[level] }
+
+// ParseLevel accepts a level by name or by number, 1 being the vaguest
+func ParseLevel(text string) (Level, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	for level, name := range levelNames {
+		if text == name || text == fmt.Sprint(int(level)) {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown hint level %q, use ingredient, ingredients or result", text)
+}
+
+// A combination the player can make right now that discovers a new element
+type Suggestion struct {
+	Ingredients [2]*search.ElementNode
+	Result      *search.ElementNode
+	Unlocks     int // Elements the result makes craftable straight away
+}
+
+// What the player gets to see of a suggestion
+type Hint struct {
+	Level       string   `json:"level"`
+	Ingredients []string `json:"ingredients"`
+	Result      string   `json:"result,omitempty"`
+	Unlocks     int      `json:"unlocks"`
+}
+
+// Suggest ranks every combination that makes an undiscovered element out of discovered ones.
+// Results that open up the most new recipes among their Children come first, then lower tiers.
+// Base elements always count as discovered, at most limit suggestions are returned
+func Suggest(graph *search.RecipeGraph, discovered []*search.ElementNode, limit int) []Suggestion {
+	known := make(map[*search.ElementNode]bool)
+	for _, element := range graph.BaseElements {
+		known[element] = true
+	}
+	for _, element := range discovered {
+		known[element] = true
+	}
+
+	suggestions := make([]Suggestion, 0)
+	for _, element := range graph.Elements[1:] {
+		if known[element] {
+			continue
+		}
+		for _, recipe := range element.Recipes {
+			if known[recipe[0]] && known[recipe[1]] {
+				suggestions = append(suggestions, Suggestion{
+					Ingredients: [2]*search.ElementNode{recipe[0], recipe[1]},
+					Result:      element,
+					Unlocks:     unlocks(element, known),
+				})
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.Unlocks != b.Unlocks {
+			return a.Unlocks > b.Unlocks
+		}
+		if a.Result.Tier != b.Result.Tier {
+			return a.Result.Tier < b.Result.Tier
+		}
+		return a.Result.Name < b.Result.Name
+	})
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+// Undiscovered children of result that become craftable once it is discovered
+func unlocks(result *search.ElementNode, known map[*search.ElementNode]bool) int {
+	count := 0
+	for _, child := range result.Children {
+		if known[child] {
+			continue
+		}
+		for _, recipe := range child.Recipes {
+			if !slices.Contains(recipe, result) {
+				continue
+			}
+			if (known[recipe[0]] || recipe[0] == result) && (known[recipe[1]] || recipe[1] == result) {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+func (suggestion Suggestion) Hint(level Level) Hint {
+	hint := Hint{
+		Level:       level.String(),
+		Ingredients: []string{suggestion.Ingredients[0].Name},
+		Unlocks:     suggestion.Unlocks,
+	}
+	if level >= BothIngredients {
+		hint.Ingredients = append(hint.Ingredients, suggestion.Ingredients[1].Name)
+	}
+	if level >= FullRecipe {
+		hint.Result = suggestion.Result.Name
+	}
+	return hint
+}
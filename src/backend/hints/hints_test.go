@@ -0,0 +1,174 @@
+package hints
+
+import (
+	"backend/scraping"
+	"backend/search"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// The algorithm package fixture
+var fixtureRecipes = scraping.RecipeEntry{
+	Element: []string{
+		"Air", "Earth", "Fire", "Water",
+		"Steam", "Lava", "Dust", "Mud", "Energy", "Pressure",
+		"Stone", "Cloud", "Rain", "Metal", "Acid rain", "Island",
+	},
+	Recipe: map[string][][]string{
+		"Air":       {{"", ""}},
+		"Earth":     {{"", ""}},
+		"Fire":      {{"", ""}},
+		"Water":     {{"", ""}},
+		"Steam":     {{"Fire", "Water"}, {"Water", "Fire"}, {"Air", "Water"}},
+		"Lava":      {{"Earth", "Fire"}},
+		"Dust":      {{"Earth", "Air"}},
+		"Mud":       {{"Earth", "Water"}, {"Dust", "Water"}},
+		"Energy":    {{"Fire", "Air"}, {"Fire", "Fire"}},
+		"Pressure":  {{"Air", "Air"}, {"Earth", "Earth"}},
+		"Stone":     {{"Lava", "Air"}, {"Lava", "Pressure"}, {"Mud", "Fire"}},
+		"Cloud":     {{"Steam", "Air"}, {"Steam", "Pressure"}},
+		"Rain":      {{"Cloud", "Water"}, {"Cloud", "Steam"}},
+		"Metal":     {{"Stone", "Fire"}, {"Stone", "Energy"}},
+		"Acid rain": {{"Rain", "Metal"}, {"Rain", "Stone"}},
+		"Island":    {{"Stone", "Water"}, {"Water", "Stone"}},
+	},
+	Tiering: map[string]int{
+		"Air": 0, "Earth": 0, "Fire": 0, "Water": 0,
+		"Steam": 1, "Lava": 1, "Dust": 1, "Energy": 1, "Pressure": 1,
+		"Mud": 2, "Cloud": 2, "Stone": 3, "Rain": 3, "Metal": 4, "Island": 4, "Acid rain": 5,
+	},
+}
+
+func fixtureGraph(t *testing.T) *search.RecipeGraph {
+	t.Helper()
+	var graph search.RecipeGraph
+	if err := search.ConstructRecipeGraph(fixtureRecipes, &graph); err != nil {
+		t.Fatal(err)
+	}
+	return &graph
+}
+
+func elements(t *testing.T, graph *search.RecipeGraph, names ...string) []*search.ElementNode {
+	t.Helper()
+	nodes := make([]*search.ElementNode, len(names))
+	for i, name := range names {
+		node, err := search.GetElementByName(graph, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes[i] = node
+	}
+	return nodes
+}
+
+// Result, ingredients and unlocks of each suggestion, in order
+func describe(suggestions []Suggestion) []string {
+	described := make([]string, len(suggestions))
+	for i, suggestion := range suggestions {
+		described[i] = fmt.Sprintf("%s + %s = %s %d",
+			suggestion.Ingredients[0].Name, suggestion.Ingredients[1].Name, suggestion.Result.Name, suggestion.Unlocks)
+	}
+	return described
+}
+
+func TestSuggest(t *testing.T) {
+	graph := fixtureGraph(t)
+
+	tests := []struct {
+		name       string
+		discovered []string
+		limit      int
+		want       []string
+	}{
+		{
+			// Most unlocks first, then lower tiers, then by name
+			name: "base elements only",
+			want: []string{
+				"Earth + Air = Dust 1", "Earth + Fire = Lava 1", "Fire + Water = Steam 1",
+				"Earth + Water = Mud 1", "Fire + Air = Energy 0", "Air + Air = Pressure 0",
+			},
+		},
+		{
+			name:  "limit",
+			limit: 2,
+			want:  []string{"Earth + Air = Dust 1", "Earth + Fire = Lava 1"},
+		},
+		{
+			// Lava and Steam are known, so only what they lead to is new
+			name:       "discovered elements",
+			discovered: []string{"Lava", "Steam"},
+			want: []string{
+				"Air + Air = Pressure 2", "Lava + Air = Stone 2",
+				"Earth + Air = Dust 1", "Steam + Air = Cloud 1", "Earth + Water = Mud 1",
+				"Fire + Air = Energy 0",
+			},
+		},
+		{
+			// A limit of zero or less returns every suggestion
+			name:       "nothing left to discover",
+			discovered: fixtureRecipes.Element,
+			limit:      -1,
+			want:       []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := describe(Suggest(graph, elements(t, graph, test.discovered...), test.limit))
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got  %q\nwant %q", got, test.want)
+			}
+		})
+	}
+}
+
+// Every suggestion is craftable from known elements and discovers something new
+func TestSuggestOnlyNewDiscoveries(t *testing.T) {
+	graph := fixtureGraph(t)
+	discovered := elements(t, graph, "Steam", "Cloud", "Lava", "Stone")
+	known := append(slices.Clone(graph.BaseElements), discovered...)
+
+	suggestions := Suggest(graph, discovered, 0)
+	if len(suggestions) == 0 {
+		t.Fatal("no suggestions")
+	}
+	for _, suggestion := range suggestions {
+		if slices.Contains(known, suggestion.Result) {
+			t.Errorf("%s is already discovered", suggestion.Result.Name)
+		}
+		for _, ingredient := range suggestion.Ingredients {
+			if !slices.Contains(known, ingredient) {
+				t.Errorf("%s needs the undiscovered %s", suggestion.Result.Name, ingredient.Name)
+			}
+		}
+	}
+}
+
+func TestHintLevels(t *testing.T) {
+	graph := fixtureGraph(t)
+	suggestion := Suggest(graph, nil, 1)[0]
+
+	tests := []struct {
+		level string
+		want  Hint
+	}{
+		{"1", Hint{Level: "ingredient", Ingredients: []string{"Earth"}, Unlocks: 1}},
+		{"Ingredients", Hint{Level: "ingredients", Ingredients: []string{"Earth", "Air"}, Unlocks: 1}},
+		{" result ", Hint{Level: "result", Ingredients: []string{"Earth", "Air"}, Result: "Dust", Unlocks: 1}},
+	}
+	for _, test := range tests {
+		level, err := ParseLevel(test.level)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := suggestion.Hint(level)
+		if got.Level != test.want.Level || got.Result != test.want.Result || got.Unlocks != test.want.Unlocks ||
+			!slices.Equal(got.Ingredients, test.want.Ingredients) {
+			t.Errorf("%q: got %+v, want %+v", test.level, got, test.want)
+		}
+	}
+
+	if _, err := ParseLevel("4"); err == nil {
+		t.Error("ParseLevel accepted level 4")
+	}
+}
@@ -1,17 +1,9 @@
 package main
 
 import (
-	"backend/algorithm"
 	"backend/scraping"
 	"backend/search"
-	"fmt"
-	"log"
-	"net/http"
-	"strconv"
-	"strings"
-
-	"github.com/gin-contrib/cors"
-	"github.com/gin-gonic/gin"
+	"backend/server"
 )
 
 func main() {
@@ -29,157 +21,18 @@ func main() {
 		panic(err)
 	}
 
-	r := gin.Default()
-	r.SetTrustedProxies([]string{"127.0.0.1"})
-	r.Use(cors.New(cors.Config{
-	    AllowOrigins:     []string{"*"}, // Mengizinkan semua origin saat pengembangan
-	    AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-	    AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-	    ExposeHeaders:    []string{"Content-Length"},
-	    AllowCredentials: true,
-	}))
-
-	// http://localhost:8080/api/recipe?element=Acid%20Rain&algo=bfs|dfs
-	r.GET("/api/recipe", func(c *gin.Context) {
-		algorithm.ResetCaches()
-		element := c.Query("element")
-		algo := strings.ToLower(c.DefaultQuery("algo", "bfs"))
-
-		if element == "" {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   true,
-				"type":    "missing_parameter",
-				"message": "Element parameter is required",
-			})
-			return
-		}
-
-		// find the node
-		node, err := search.GetElementByName(&graph, element)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error":   true,
-				"type":    "element_not_found",
-				"message": fmt.Sprintf("Element '%s' not found", element),
-			})
-			return
-		}
-
-		switch algo {
-		case "bfs":
-			big, visitedCount := algorithm.ReverseBFS(node, 1)
-			paths := algorithm.ExpandPaths(*big, element, 1)
-
-			c.JSON(http.StatusOK, gin.H{
-				"error": false,
-				"data": gin.H{
-					"algo":         "bfs",
-					"element":      element,
-					"paths":        paths,          // ← what your frontend expects
-					"visitedNodes": visitedCount,
-				},
-			})
-		case "dfs":
-			var nodeVisited int
-			result := algorithm.DFS(node, &graph, 1, &nodeVisited)
-			log.Printf("Jumlah node yang dikunjungi: %d\n", nodeVisited)
-
-			if len(result) > 0 {
-				c.JSON(http.StatusOK, gin.H{
-					"error": false,
-					"data": gin.H{
-						"nodes":        result[0],
-						"visitedNodes": nodeVisited,
-					},
-				})
-			}
-		default:
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   true,
-				"type":    "invalid_algorithm",
-				"message": "Algorithm must be 'bfs' or 'dfs'",
-			})
-		}
-	})
-
-	r.GET("/api/recipes", func(c *gin.Context) {
-		element := c.Query("element")
-		algo := strings.ToLower(c.DefaultQuery("algo", "bfs"))
-
-		if element == "" {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   true,
-				"type":    "missing_parameter",
-				"message": "Element parameter is required",
-			})
-			return
-		}
-
-		max, _ := strconv.Atoi(c.DefaultQuery("max", "5"))
-		if max <= 0 {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   true,
-				"type":    "invalid_parameter",
-				"message": "Max parameter must be greater than 0",
-			})
-			return
-		}
-
-		node, err := search.GetElementByName(&graph, element)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error":   true,
-				"type":    "element_not_found",
-				"message": fmt.Sprintf("Element '%s' not found", element),
-			})
-			return
-		}
-
-		algorithm.ResetCaches()
-		
-		switch algo {
-		case "bfs":
-			big, visited := algorithm.ReverseBFS(node, 1)
-			//print big in terminal
-
-			log.Printf("%+v", big)
-			p := algorithm.ExpandPaths(*big, element, max)
-
-			if len(p) > max {
-				p = p[:max]
-			}
-
-			c.JSON(http.StatusOK, gin.H{
-				"error": false,
-				"data": gin.H{
-					"algo":         "bfs",
-					"element":      element,
-					"paths":        p,
-					"visitedNodes": visited,
-				},
-			})
-		case "dfs":
-			var nodeVisited int
-			results := algorithm.DFS(node, &graph, max, &nodeVisited)
-
-			c.JSON(http.StatusOK, gin.H{
-				"error": false,
-				"data": gin.H{
-					"element":      element,
-					"algo":         algo,
-					"paths":        results,
-					"visitedNodes": nodeVisited,
-				},
-			})
-		default:
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   true,
-				"type":    "invalid_algorithm",
-				"message": "Algorithm must be 'bfs' or 'dfs'",
-			})
-			return
-		}
-	})
+	server.New(&graph, server.Config{Reload: loadScraped}).Run(":8080")
+}
 
-	r.Run(":8080")
+// Reads the scraped recipes again for /api/dataset/reload, without scraping
+func loadScraped() (*search.RecipeGraph, error) {
+	recipes, err := scraping.GetScrapedRecipesJSON()
+	if err != nil {
+		return nil, err
+	}
+	var graph search.RecipeGraph
+	if err := search.ConstructRecipeGraph(recipes, &graph); err != nil {
+		return nil, err
+	}
+	return &graph, nil
 }
@@ -0,0 +1,407 @@
+// Package repl is an interactive shell for exploring a loaded recipe graph offline
+package repl
+
+import (
+	"backend/algorithm"
+	"backend/hints"
+	"backend/search"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Commands the shell understands, in the order help lists them
+var commands = []struct {
+	name, args, help string
+}{
+	{"recipe", "<element> [n]", "smallest recipe trees, elements you have are not expanded"},
+	{"uses", "<element>", "combinations the element is an ingredient of"},
+	{"path", "<from> <to>", "shortest chain of combinations leading from one element to another"},
+	{"have", "[element...]", "add elements to your inventory, or list it"},
+	{"drop", "<element...>", "remove elements from your inventory, all of them when none is given"},
+	{"next", "[n]", "combinations your inventory can make that discover something new"},
+	{"help", "", "this list"},
+	{"quit", "", "leave the shell"},
+}
+
+// Shell keeps the graph, the inventory and where output goes between commands
+type Shell struct {
+	graph     *search.RecipeGraph
+	byName    map[string]*search.ElementNode // Lower case names, input is case insensitive
+	names     []string                       // Sorted, for completion
+	inventory map[*search.ElementNode]bool
+	out       io.Writer
+	interrupt <-chan struct{} // Ctrl+C while a command runs, nil when not read from a terminal
+}
+
+func New(graph *search.RecipeGraph, out io.Writer) *Shell {
+	shell := &Shell{
+		graph:     graph,
+		byName:    make(map[string]*search.ElementNode),
+		inventory: make(map[*search.ElementNode]bool),
+		out:       out,
+	}
+	for _, element := range graph.Elements[1:] {
+		shell.byName[strings.ToLower(element.Name)] = element
+		shell.names = append(shell.names, element.Name)
+	}
+	sort.Strings(shell.names)
+	return shell
+}
+
+// Execute runs one line. The returned error is meant for the user, the shell keeps going
+// unless it is the quit command
+func (shell *Shell) Execute(line string) (quit bool, err error) {
+	words := fields(line)
+	if len(words) == 0 {
+		return false, nil
+	}
+	command, args := strings.ToLower(words[0]), words[1:]
+
+	switch command {
+	case "recipe":
+		err = shell.recipe(args)
+	case "uses":
+		err = shell.uses(args)
+	case "path":
+		err = shell.path(args)
+	case "have":
+		err = shell.have(args)
+	case "drop":
+		err = shell.drop(args)
+	case "next":
+		err = shell.next(args)
+	case "help", "?":
+		shell.help()
+	case "quit", "exit":
+		return true, nil
+	default:
+		err = fmt.Errorf("unknown command %s, type help for the list", command)
+	}
+	return false, err
+}
+
+func (shell *Shell) help() {
+	for _, command := range commands {
+		fmt.Fprintf(shell.out, "  %-28s %s\n", command.name+" "+command.args, command.help)
+	}
+}
+
+/* ----------------------------------------- Arguments ----------------------------------------------- */
+
+// Splits on spaces except inside double quotes, so "Acid rain" stays one word.
+// Quoted words keep their quotes so they are never joined with their neighbours
+func fields(line string) []string {
+	words := make([]string, 0)
+	var word strings.Builder
+	quoted := false
+	for _, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		}
+		if r == ' ' && !quoted {
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+			continue
+		}
+		word.WriteRune(r)
+	}
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+func isQuoted(word string) bool { return strings.HasPrefix(word, `"`) }
+
+// Element names contain spaces, so words are matched greedily: the longest run of words
+// naming an element wins, then matching goes on after it
+func (shell *Shell) elements(words []string) ([]*search.ElementNode, error) {
+	elements := make([]*search.ElementNode, 0)
+	for start := 0; start < len(words); {
+		last := start + 1
+		for !isQuoted(words[start]) && last < len(words) && !isQuoted(words[last]) {
+			last++
+		}
+		found := false
+		for end := last; end > start; end-- {
+			name := strings.ToLower(strings.Trim(strings.Join(words[start:end], " "), `"`))
+			if element, ok := shell.byName[name]; ok {
+				elements = append(elements, element)
+				start, found = end, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no element named %s", strings.Trim(strings.Join(words[start:last], " "), `"`))
+		}
+	}
+	return elements, nil
+}
+
+// Optional count at the end of the arguments
+func splitCount(words []string, fallback int) ([]string, int) {
+	if len(words) == 0 {
+		return words, fallback
+	}
+	var count int
+	if _, err := fmt.Sscanf(words[len(words)-1], "%d", &count); err != nil || count <= 0 {
+		return words, fallback
+	}
+	return words[:len(words)-1], count
+}
+
+func (shell *Shell) element(words []string) (*search.ElementNode, error) {
+	elements, err := shell.elements(words)
+	if err != nil {
+		return nil, err
+	}
+	if len(elements) != 1 {
+		return nil, errors.New("expected exactly one element")
+	}
+	return elements[0], nil
+}
+
+/* ----------------------------------------- Commands ----------------------------------------------- */
+
+func (shell *Shell) recipe(args []string) error {
+	args, count := splitCount(args, 1)
+	if len(args) == 0 {
+		return errors.New("usage: recipe <element> [n]")
+	}
+	target, err := shell.element(args)
+	if err != nil {
+		return err
+	}
+	if shell.inventory[target] {
+		fmt.Fprintf(shell.out, "you already have %s\n", target.Name)
+		return nil
+	}
+
+	// A* yields the trees with the fewest combinations first, and owned elements cost none.
+	// It gives up on its own after a bounded number of partial trees, an interrupted search is
+	// left to do so in the background
+	type outcome struct {
+		result algorithm.SearchResult
+		err    error
+	}
+	req := algorithm.SearchRequest{Target: target, Graph: shell.owned(), MaxPaths: count}
+	found := make(chan outcome, 1)
+	go func() {
+		result, err := algorithm.Run("astar", req)
+		found <- outcome{result, err}
+	}()
+	var result algorithm.SearchResult
+	select {
+	case done := <-found:
+		if done.err != nil {
+			return done.err
+		}
+		result = done.result
+	case <-shell.interrupt:
+		return errors.New("interrupted")
+	}
+	if len(result.Trees) == 0 {
+		return fmt.Errorf("%s cannot be crafted", target.Name)
+	}
+	for i, tree := range result.Trees {
+		if len(result.Trees) > 1 {
+			fmt.Fprintf(shell.out, "Recipe %d\n", i+1)
+		}
+		shell.printTree(tree)
+	}
+	return nil
+}
+
+// The graph with every element of the inventory taken as a base element, so searches end their
+// branches there
+func (shell *Shell) owned() *search.RecipeGraph {
+	graph := *shell.graph
+	graph.BaseElements = slices.Clone(graph.BaseElements)
+	for element := range shell.inventory {
+		if !slices.Contains(graph.BaseElements, element) {
+			graph.BaseElements = append(graph.BaseElements, element)
+		}
+	}
+	return &graph
+}
+
+// Box drawing tree, root first. Elements from the inventory end their branch
+func (shell *Shell) printTree(tree *algorithm.RecipeTree) {
+	fmt.Fprintln(shell.out, tree.Element)
+	shell.printIngredients(tree, "")
+}
+
+func (shell *Shell) printIngredients(tree *algorithm.RecipeTree, indent string) {
+	for i, ingredient := range tree.Ingredients {
+		branch, next := "├── ", "│   "
+		if i == len(tree.Ingredients)-1 {
+			branch, next = "└── ", "    "
+		}
+		label := ingredient.Element
+		element := shell.byName[strings.ToLower(ingredient.Element)]
+		if shell.inventory[element] {
+			fmt.Fprintf(shell.out, "%s%s%s (have)\n", indent, branch, label)
+			continue
+		}
+		if ingredient.IsLeaf() {
+			label += " (base)"
+		}
+		fmt.Fprintf(shell.out, "%s%s%s\n", indent, branch, label)
+		shell.printIngredients(ingredient, indent+next)
+	}
+}
+
+func (shell *Shell) uses(args []string) error {
+	element, err := shell.element(args)
+	if err != nil {
+		return err
+	}
+	lines := make([]string, 0)
+	for _, child := range element.Children {
+		for _, recipe := range child.Recipes {
+			if recipe[0] == element || recipe[1] == element {
+				lines = append(lines, fmt.Sprintf("%s + %s = %s", recipe[0].Name, recipe[1].Name, child.Name))
+			}
+		}
+	}
+	if len(lines) == 0 {
+		fmt.Fprintf(shell.out, "%s is not used in any recipe\n", element.Name)
+		return nil
+	}
+	slices.Sort(lines)
+	lines = slices.Compact(lines)
+	for _, line := range lines {
+		fmt.Fprintln(shell.out, line)
+	}
+	return nil
+}
+
+func (shell *Shell) path(args []string) error {
+	elements, err := shell.elements(args)
+	if err != nil {
+		return err
+	}
+	if len(elements) != 2 {
+		return errors.New("usage: path <from> <to>, quote names when they are ambiguous")
+	}
+	from, to := elements[0], elements[1]
+	if from == to {
+		return fmt.Errorf("%s is where you start", from.Name)
+	}
+
+	// The tree is grown along the shortest chain, first ingredient first
+	tree, _, _, err := algorithm.BidirectionalSearch(from, to, shell.graph, nil)
+	if errors.Is(err, algorithm.ErrNoRecipe) {
+		return fmt.Errorf("%s does not lead to %s", from.Name, to.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	chain := make([]string, 0)
+	for step := tree; step.Element != from.Name; step = step.Ingredients[0] {
+		chain = append(chain, fmt.Sprintf("%s + %s = %s", step.Ingredients[0].Element, step.Ingredients[1].Element, step.Element))
+	}
+	slices.Reverse(chain)
+	for i, line := range chain {
+		fmt.Fprintf(shell.out, "%d. %s\n", i+1, line)
+	}
+	return nil
+}
+
+func (shell *Shell) have(args []string) error {
+	elements, err := shell.elements(args)
+	if err != nil {
+		return err
+	}
+	for _, element := range elements {
+		shell.inventory[element] = true
+	}
+	if len(shell.inventory) == 0 {
+		fmt.Fprintln(shell.out, "your inventory is empty")
+		return nil
+	}
+	fmt.Fprintln(shell.out, strings.Join(shell.inventoryNames(), ", "))
+	return nil
+}
+
+func (shell *Shell) drop(args []string) error {
+	if len(args) == 0 {
+		clear(shell.inventory)
+		return nil
+	}
+	elements, err := shell.elements(args)
+	if err != nil {
+		return err
+	}
+	for _, element := range elements {
+		delete(shell.inventory, element)
+	}
+	return nil
+}
+
+func (shell *Shell) next(args []string) error {
+	_, limit := splitCount(args, 10)
+	discovered := make([]*search.ElementNode, 0, len(shell.inventory))
+	for element := range shell.inventory {
+		discovered = append(discovered, element)
+	}
+	suggestions := hints.Suggest(shell.graph, discovered, limit)
+	if len(suggestions) == 0 {
+		fmt.Fprintln(shell.out, "nothing new can be made from your inventory")
+		return nil
+	}
+	for _, suggestion := range suggestions {
+		fmt.Fprintf(shell.out, "%s + %s = %s\n", suggestion.Ingredients[0].Name, suggestion.Ingredients[1].Name, suggestion.Result.Name)
+	}
+	return nil
+}
+
+func (shell *Shell) inventoryNames() []string {
+	names := make([]string, 0, len(shell.inventory))
+	for element := range shell.inventory {
+		names = append(names, element.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+/* ----------------------------------------- Completion ----------------------------------------------- */
+
+// Complete returns every way to finish the word or element name under the cursor at the end of line.
+// The first word completes to a command, later ones to element names, which may span several words
+func (shell *Shell) Complete(line string) []string {
+	if !strings.Contains(line, " ") {
+		candidates := make([]string, 0)
+		for _, command := range commands {
+			if strings.HasPrefix(command.name, strings.ToLower(line)) {
+				candidates = append(candidates, command.name+" ")
+			}
+		}
+		return candidates
+	}
+
+	// Try the longest tail first so "Acid r" completes as one name rather than "r"
+	candidates := make([]string, 0)
+	for start := strings.Index(line, " ") + 1; start <= len(line); start++ {
+		if start > 0 && line[start-1] != ' ' {
+			continue
+		}
+		partial := strings.ToLower(strings.TrimLeft(line[start:], `"`))
+		for _, name := range shell.names {
+			if strings.HasPrefix(strings.ToLower(name), partial) {
+				candidates = append(candidates, line[:start]+name)
+			}
+		}
+		if len(candidates) > 0 {
+			return candidates
+		}
+	}
+	return candidates
+}
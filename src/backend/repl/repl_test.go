@@ -0,0 +1,166 @@
+package repl
+
+import (
+	"backend/scraping"
+	"backend/search"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testShell(t *testing.T) *Shell {
+	t.Helper()
+	recipes := scraping.RecipeEntry{
+		Element: []string{"Air", "Fire", "Water", "Steam", "Cloud", "Rain", "Acid", "Acid rain", "Storm"},
+		Recipe: map[string][][]string{
+			"Air":       {{"", ""}},
+			"Fire":      {{"", ""}},
+			"Water":     {{"", ""}},
+			"Steam":     {{"Fire", "Water"}, {"Acid rain", "Air"}}, // The second one breaks the tier rule
+			"Cloud":     {{"Steam", "Air"}},
+			"Rain":      {{"Cloud", "Water"}},
+			"Acid":      {{"Fire", "Rain"}},
+			"Acid rain": {{"Acid", "Rain"}},
+			"Storm":     {{"Cloud", "Fire"}, {"Acid", "Air"}},
+		},
+		Tiering: map[string]int{
+			"Air": 0, "Fire": 0, "Water": 0, "Steam": 1, "Cloud": 2, "Rain": 3, "Acid": 4, "Acid rain": 5, "Storm": 5,
+		},
+	}
+	var graph search.RecipeGraph
+	if err := search.ConstructRecipeGraph(recipes, &graph); err != nil {
+		t.Fatal(err)
+	}
+	return New(&graph, io.Discard)
+}
+
+// Names with spaces complete as a whole and are read back as one element
+func TestElementNamesSpanWords(t *testing.T) {
+	shell := testShell(t)
+
+	if got, want := shell.Complete("have Fire acid r"), []string{"have Fire Acid rain"}; !slices.Equal(got, want) {
+		t.Errorf("completion: got %q, want %q", got, want)
+	}
+	if got, want := shell.Complete("re"), []string{"recipe "}; !slices.Equal(got, want) {
+		t.Errorf("command completion: got %q, want %q", got, want)
+	}
+
+	for line, want := range map[string][]string{
+		"acid rain fire":     {"Acid rain", "Fire"},
+		`"Acid" rain`:        {"Acid", "Rain"},
+		"water acid rain":    {"Water", "Acid rain"},
+		"steam cloud  RAIN ": {"Steam", "Cloud", "Rain"},
+	} {
+		elements, err := shell.elements(fields(line))
+		if err != nil {
+			t.Fatalf("%s: %v", line, err)
+		}
+		got := make([]string, len(elements))
+		for i, element := range elements {
+			got[i] = element.Name
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("%s: got %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestInventoryStopsTreeExpansion(t *testing.T) {
+	shell := testShell(t)
+	var out strings.Builder
+	shell.out = &out
+
+	for _, line := range []string{"have cloud", "recipe acid rain"} {
+		if _, err := shell.Execute(line); err != nil {
+			t.Fatalf("%s: %v", line, err)
+		}
+	}
+	if !strings.Contains(out.String(), "Cloud (have)") || strings.Contains(out.String(), "Steam") {
+		t.Errorf("owned Cloud was expanded:\n%s", out.String())
+	}
+}
+
+// Owned elements cost nothing, so the smallest tree can change with the inventory
+func TestRecipeCountsInventory(t *testing.T) {
+	tests := []struct {
+		lines []string
+		want  string
+		not   string
+	}{
+		{[]string{"recipe storm"}, "Cloud", "Acid"},
+		{[]string{"have acid", "recipe storm"}, "Acid (have)", "Cloud"},
+	}
+	for _, test := range tests {
+		shell := testShell(t)
+		var out strings.Builder
+		shell.out = &out
+		for _, line := range test.lines {
+			if _, err := shell.Execute(line); err != nil {
+				t.Fatalf("%s: %v", line, err)
+			}
+		}
+		if !strings.Contains(out.String(), test.want) || strings.Contains(out.String(), test.not) {
+			t.Errorf("%q: want %s and no %s in\n%s", test.lines, test.want, test.not, out.String())
+		}
+	}
+}
+
+// Ctrl+C typed while a command runs reaches the command, other keys wait for the next line
+func TestInterruptWhileCommandRuns(t *testing.T) {
+	keys := readKeys(bufio.NewReader(strings.NewReader("ab\x03c")))
+	interrupt := make(chan struct{}, 1)
+
+	_, err := keys.during(func() (bool, error) {
+		select {
+		case <-interrupt:
+			return false, errors.New("interrupted")
+		case <-time.After(5 * time.Second):
+			return false, nil
+		}
+	}, interrupt)
+	if err == nil {
+		t.Fatal("the command was not interrupted")
+	}
+
+	var typed []byte
+	for {
+		key, err := keys.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		typed = append(typed, key)
+	}
+	if string(typed) != "abc" {
+		t.Errorf("kept %q, want %q", typed, "abc")
+	}
+}
+
+// Chains only use recipes the game allows
+func TestPathFollowsUsableRecipes(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"path fire acid rain", "1. Fire + Rain = Acid\n2. Acid + Rain = Acid rain\n"},
+		{"path air steam", "Air does not lead to Steam\n"},
+	}
+	for _, test := range tests {
+		shell := testShell(t)
+		var out strings.Builder
+		shell.out = &out
+		if _, err := shell.Execute(test.line); err != nil {
+			fmt.Fprintln(&out, err)
+		}
+		if out.String() != test.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.line, out.String(), test.want)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+const prompt = "alchemy> "
+
+// Key codes the line editor reacts to in raw mode
+const (
+	keyInterrupt = 3  // Ctrl+C clears the line, or stops a running search
+	keyEOF       = 4  // Ctrl+D on an empty line leaves
+	keyBackspace = 8  // Ctrl+H
+	keyTab       = 9  // Completes
+	keyEnter     = 13 // Raw mode sends a carriage return
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// Run reads commands from in until quit or end of input. On a terminal the line is edited
+// in raw mode for tab completion, anything else such as a pipe is read line by line without prompts.
+// Raw mode turns off the interrupt signal, so keys are read in the background and a Ctrl+C
+// typed while a command runs stops its search instead
+func (shell *Shell) Run(in *os.File) error {
+	restore, err := makeRaw(int(in.Fd()))
+	if err != nil {
+		return shell.runLines(in)
+	}
+	defer restore()
+
+	keys := readKeys(bufio.NewReader(in))
+	interrupt := make(chan struct{}, 1)
+	shell.interrupt = interrupt
+	fmt.Fprintf(shell.out, "%d elements loaded, type help for commands\r\n", len(shell.names))
+	editor := &lineEditor{in: keys, out: shell.out, complete: shell.Complete}
+	for {
+		line, err := editor.readLine()
+		if err == io.EOF {
+			fmt.Fprint(shell.out, "\r\n")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// Raw mode does not turn \n into \r\n, so output is collected and translated
+		var output strings.Builder
+		shell.out = &output
+		select {
+		case <-interrupt: // Typed after the last command was done
+		default:
+		}
+		quit, err := keys.during(func() (bool, error) { return shell.Execute(line) }, interrupt)
+		shell.out = editor.out
+		if err != nil {
+			fmt.Fprintln(&output, err)
+		}
+		fmt.Fprint(shell.out, strings.ReplaceAll(output.String(), "\n", "\r\n"))
+		if quit {
+			return nil
+		}
+	}
+}
+
+func (shell *Shell) runLines(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		quit, err := shell.Execute(scanner.Text())
+		if err != nil {
+			fmt.Fprintln(shell.out, err)
+		}
+		if quit {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// Keys from the terminal, read ahead by a goroutine so they can be looked at while a command runs
+type keyReader struct {
+	keys  chan byte
+	err   error  // Why reading stopped, set before keys is closed
+	typed []byte // Typed while a command ran, for the next line
+}
+
+func readKeys(in io.ByteReader) *keyReader {
+	reader := &keyReader{keys: make(chan byte)}
+	go func() {
+		for {
+			key, err := in.ReadByte()
+			if err != nil {
+				reader.err = err
+				close(reader.keys)
+				return
+			}
+			reader.keys <- key
+		}
+	}()
+	return reader
+}
+
+func (reader *keyReader) ReadByte() (byte, error) {
+	if len(reader.typed) > 0 {
+		key := reader.typed[0]
+		reader.typed = reader.typed[1:]
+		return key, nil
+	}
+	key, ok := <-reader.keys
+	if !ok {
+		return 0, reader.err
+	}
+	return key, nil
+}
+
+// Runs command in the background, turning a Ctrl+C into a signal on interrupt and keeping
+// every other key for the next line
+func (reader *keyReader) during(command func() (bool, error), interrupt chan<- struct{}) (bool, error) {
+	type outcome struct {
+		quit bool
+		err  error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		quit, err := command()
+		done <- outcome{quit, err}
+	}()
+	keys := reader.keys
+	for {
+		select {
+		case result := <-done:
+			return result.quit, result.err
+		case key, ok := <-keys:
+			switch {
+			case !ok:
+				keys = nil // Reported by the next ReadByte
+			case key == keyInterrupt:
+				select {
+				case interrupt <- struct{}{}:
+				default:
+				}
+			default:
+				reader.typed = append(reader.typed, key)
+			}
+		}
+	}
+}
+
+// Minimal line editing: typing at the end of the line, backspace, tab completion.
+// Escape sequences such as the arrow keys are read and ignored
+type lineEditor struct {
+	in       io.ByteReader
+	out      io.Writer
+	complete func(line string) []string
+	line     []byte
+	tabbed   bool // The previous key was a tab without a single completion
+}
+
+func (editor *lineEditor) readLine() (string, error) {
+	editor.line = editor.line[:0]
+	editor.tabbed = false
+	editor.redraw()
+	for {
+		key, err := editor.in.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		if key != keyTab {
+			editor.tabbed = false
+		}
+
+		switch key {
+		case keyEnter, '\n':
+			fmt.Fprint(editor.out, "\r\n")
+			return string(editor.line), nil
+		case keyEOF:
+			if len(editor.line) == 0 {
+				return "", io.EOF
+			}
+		case keyInterrupt:
+			fmt.Fprint(editor.out, "^C\r\n")
+			editor.line = editor.line[:0]
+		case keyBackspace, keyDelete:
+			if len(editor.line) > 0 {
+				_, size := utf8.DecodeLastRune(editor.line)
+				editor.line = editor.line[:len(editor.line)-size]
+			}
+		case keyTab:
+			editor.tab()
+		case keyEscape:
+			editor.skipEscape()
+		default:
+			if key >= ' ' {
+				editor.line = append(editor.line, key)
+			}
+		}
+		editor.redraw()
+	}
+}
+
+// One candidate is filled in, several extend the line to what they share and
+// a second tab lists them
+func (editor *lineEditor) tab() {
+	candidates := editor.complete(string(editor.line))
+	switch len(candidates) {
+	case 0:
+		return
+	case 1:
+		editor.line = []byte(candidates[0])
+		return
+	}
+
+	shared := candidates[0]
+	for _, candidate := range candidates[1:] {
+		for !strings.HasPrefix(strings.ToLower(candidate), strings.ToLower(shared)) {
+			shared = shared[:len(shared)-1]
+		}
+	}
+	if len(shared) > len(editor.line) {
+		editor.line = []byte(shared)
+		return
+	}
+
+	if editor.tabbed {
+		fmt.Fprint(editor.out, "\r\n")
+		cut := strings.LastIndex(shared, " ") + 1
+		for _, candidate := range candidates {
+			fmt.Fprintf(editor.out, "%s\r\n", candidate[cut:])
+		}
+	}
+	editor.tabbed = true
+}
+
+// CSI sequences end with a byte in @..~, SS3 ones (ESC O x) are one byte long
+func (editor *lineEditor) skipEscape() {
+	next, err := editor.in.ReadByte()
+	if err != nil {
+		return
+	}
+	switch next {
+	case '[':
+		for {
+			b, err := editor.in.ReadByte()
+			if err != nil || (b >= '@' && b <= '~') {
+				return
+			}
+		}
+	case 'O':
+		editor.in.ReadByte()
+	}
+}
+
+func (editor *lineEditor) redraw() {
+	fmt.Fprintf(editor.out, "\r\x1b[K%s%s", prompt, editor.line)
+}
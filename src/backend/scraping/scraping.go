@@ -23,6 +23,11 @@ type RecipeEntry struct {
 }
 
 func ScrapeRecipes(scrapeIcon bool) error {
+	return ScrapeRecipesTo(scrapingResultPath, scrapeIcon)
+}
+
+// Same as ScrapeRecipes, writing the dataset to the given file
+func ScrapeRecipesTo(filename string, scrapeIcon bool) error {
 	url := "https://little-alchemy.fandom.com/wiki/Elements_(Little_Alchemy_2)"
 	icons_path := "scraping/icons/"
 	startTime := time.Now()
@@ -169,7 +174,7 @@ func ScrapeRecipes(scrapeIcon bool) error {
 	})
 
 	// Export the recipes to JSON file
-	filename, err := exportJSON(recipesJSON)
+	filename, err = exportJSON(filename, recipesJSON)
 	if err != nil {
 		fmt.Println("Error:", err)
 		return err
@@ -202,8 +207,12 @@ func ScrapeRecipes(scrapeIcon bool) error {
 }
 
 func GetScrapedRecipesJSON() (RecipeEntry, error) {
+	return ReadRecipesJSON(scrapingResultPath)
+}
+
+// Reads a dataset written by ScrapeRecipes from any path
+func ReadRecipesJSON(filename string) (RecipeEntry, error) {
 	// Read the JSON file
-	filename := scrapingResultPath
 	file, err := os.Open(filename)
 	if err != nil {
 		fmt.Println("Error:", err)
@@ -222,9 +231,8 @@ func GetScrapedRecipesJSON() (RecipeEntry, error) {
 	return recipesJSON, nil
 }
 
-func exportJSON(recipesJSON RecipeEntry) (string, error) {
+func exportJSON(filename string, recipesJSON RecipeEntry) (string, error) {
 	// Create the JSON file
-	filename := scrapingResultPath
 	file, err := os.Create(filename)
 	if err != nil {
 		fmt.Println("Error:", err)
@@ -0,0 +1,183 @@
+package search
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+)
+
+// Per element numbers describing how hard it is to craft and how much depends on it
+type ElementStats struct {
+	Name            string  `json:"name"`
+	Tier            int     `json:"tier"`
+	MinimalTreeSize int     `json:"minimalTreeSize"` // Fewest combinations to craft it, -1 when it cannot be crafted
+	Recipes         int     `json:"recipes"`
+	Children        int     `json:"children"`
+	DownstreamReach int     `json:"downstreamReach"` // Elements that transitively need it as an ingredient
+	Importance      float64 `json:"importance"`      // Share of craftable elements whose smallest tree contains it
+	Difficulty      float64 `json:"difficulty"`      // 0 for base elements up to 1 for uncraftable ones
+}
+
+// Weights of the difficulty score, each part is normalized to 0..1 first
+const (
+	difficultySizeWeight    = 0.5 // Larger smallest tree is harder
+	difficultyTierWeight    = 0.3 // Higher tier is harder
+	difficultyRecipesWeight = 0.2 // Fewer alternative recipes is harder
+)
+
+// Stats of every element of a graph. The graph is not expected to change once loaded,
+// so everything is computed once up front
+type Analytics struct {
+	byName map[string]*ElementStats
+	all    []*ElementStats // In graph order
+}
+
+func Analyze(graph *RecipeGraph) *Analytics {
+	elements := graph.Elements[1:]
+	analytics := &Analytics{byName: make(map[string]*ElementStats)}
+
+	minimal := MinimalRecipes(graph)
+
+	// Count every element appearing in another element's smallest tree
+	appearances := make(map[*ElementNode]int)
+	craftable := 0
+	for _, target := range elements {
+		if _, ok := minimal[target]; !ok {
+			continue
+		}
+		craftable++
+		seen := map[*ElementNode]bool{target: true}
+		stack := slices.Clone(minimal[target].Recipe)
+		for len(stack) > 0 {
+			element := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if seen[element] {
+				continue
+			}
+			seen[element] = true
+			appearances[element]++
+			stack = append(stack, minimal[element].Recipe...)
+		}
+	}
+
+	maxSize, maxTier, maxRecipes := 1, 1, 1
+	for _, element := range elements {
+		maxSize = max(maxSize, minimal[element].Size)
+		maxTier = max(maxTier, element.Tier)
+		maxRecipes = max(maxRecipes, recipeCount(element))
+	}
+
+	for _, element := range elements {
+		stats := &ElementStats{
+			Name:            element.Name,
+			Tier:            element.Tier,
+			MinimalTreeSize: -1,
+			Recipes:         recipeCount(element),
+			Children:        len(element.Children),
+			DownstreamReach: downstreamReach(element),
+		}
+		if craftable > 1 {
+			stats.Importance = float64(appearances[element]) / float64(craftable-1)
+		}
+
+		if recipe, ok := minimal[element]; ok {
+			size := recipe.Size
+			stats.MinimalTreeSize = size
+			if size > 0 {
+				stats.Difficulty = difficultySizeWeight*float64(size)/float64(maxSize) +
+					difficultyTierWeight*float64(element.Tier)/float64(maxTier) +
+					difficultyRecipesWeight*(1-float64(stats.Recipes)/float64(maxRecipes))
+			}
+		} else {
+			stats.Difficulty = 1
+		}
+		stats.Difficulty = math.Round(stats.Difficulty*1000) / 1000
+		stats.Importance = math.Round(stats.Importance*1000) / 1000
+
+		analytics.byName[element.Name] = stats
+		analytics.all = append(analytics.all, stats)
+	}
+	return analytics
+}
+
+// Recipes made of two real elements, base elements only list the sentinel
+func recipeCount(element *ElementNode) int {
+	count := 0
+	for _, recipe := range element.Recipes {
+		if len(recipe) == 2 && recipe[0].ID != 0 && recipe[1].ID != 0 {
+			count++
+		}
+	}
+	return count
+}
+
+func downstreamReach(element *ElementNode) int {
+	seen := map[*ElementNode]bool{element: true}
+	queue := slices.Clone(element.Children)
+	for len(queue) > 0 {
+		child := queue[0]
+		queue = queue[1:]
+		if seen[child] {
+			continue
+		}
+		seen[child] = true
+		queue = append(queue, child.Children...)
+	}
+	return len(seen) - 1
+}
+
+func (analytics *Analytics) Of(name string) (ElementStats, error) {
+	stats, ok := analytics.byName[name]
+	if !ok {
+		return ElementStats{}, fmt.Errorf("element with name %s not found", name)
+	}
+	return *stats, nil
+}
+
+// Metrics elements can be ranked by
+var RankingMetrics = []string{"difficulty", "importance", "downstreamReach", "minimalTreeSize", "recipes", "children", "tier"}
+
+func (stats *ElementStats) metric(name string) float64 {
+	switch name {
+	case "importance":
+		return stats.Importance
+	case "downstreamReach":
+		return float64(stats.DownstreamReach)
+	case "minimalTreeSize":
+		return float64(stats.MinimalTreeSize)
+	case "recipes":
+		return float64(stats.Recipes)
+	case "children":
+		return float64(stats.Children)
+	case "tier":
+		return float64(stats.Tier)
+	default:
+		return stats.Difficulty
+	}
+}
+
+// Rank orders elements by one of RankingMetrics, highest first unless ascending.
+// Ties keep graph order, at most limit elements are returned when limit is positive
+func (analytics *Analytics) Rank(metric string, ascending bool, limit int) ([]ElementStats, error) {
+	if !slices.Contains(RankingMetrics, metric) {
+		return nil, fmt.Errorf("unknown metric %s", metric)
+	}
+
+	ranked := slices.Clone(analytics.all)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ascending {
+			return ranked[i].metric(metric) < ranked[j].metric(metric)
+		}
+		return ranked[i].metric(metric) > ranked[j].metric(metric)
+	})
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	out := make([]ElementStats, len(ranked))
+	for i, stats := range ranked {
+		out[i] = *stats
+	}
+	return out, nil
+}
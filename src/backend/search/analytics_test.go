@@ -0,0 +1,111 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	analytics := Analyze(fixtureGraph(t))
+
+	tests := []ElementStats{
+		{Name: "Air", Tier: 0, MinimalTreeSize: 0, Recipes: 0, Children: 6, DownstreamReach: 12, Importance: 0.6, Difficulty: 0},
+		{Name: "Water", Tier: 0, MinimalTreeSize: 0, Recipes: 0, Children: 4, DownstreamReach: 9, Importance: 0.4, Difficulty: 0},
+		{Name: "Lava", Tier: 1, MinimalTreeSize: 1, Recipes: 1, Children: 1, DownstreamReach: 5, Importance: 0.267, Difficulty: 0.267},
+		{Name: "Stone", Tier: 3, MinimalTreeSize: 2, Recipes: 3, Children: 4, DownstreamReach: 4, Importance: 0.2, Difficulty: 0.317},
+		{Name: "Acid rain", Tier: 5, MinimalTreeSize: 6, Recipes: 2, Children: 0, DownstreamReach: 0, Importance: 0, Difficulty: 0.817},
+		// Its only recipe names an element the dataset lacks
+		{Name: "Sun", Tier: 6, MinimalTreeSize: -1, Recipes: 0, Children: 0, DownstreamReach: 0, Importance: 0, Difficulty: 1},
+		// Its only recipe needs a higher tier ingredient
+		{Name: "Glass", Tier: 2, MinimalTreeSize: -1, Recipes: 1, Children: 0, DownstreamReach: 0, Importance: 0, Difficulty: 1},
+	}
+	for _, want := range tests {
+		t.Run(want.Name, func(t *testing.T) {
+			got, err := analytics.Of(want.Name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("got  %+v\nwant %+v", got, want)
+			}
+		})
+	}
+
+	if _, err := analytics.Of("Gold"); err == nil {
+		t.Error("Of found an element the graph lacks")
+	}
+}
+
+func TestRank(t *testing.T) {
+	analytics := Analyze(fixtureGraph(t))
+
+	tests := []struct {
+		metric    string
+		ascending bool
+		limit     int
+		want      []string
+	}{
+		// Ties keep graph order
+		{"minimalTreeSize", false, 3, []string{"Acid rain", "Rain", "Metal"}},
+		{"minimalTreeSize", true, 2, []string{"Sun", "Glass"}},
+		{"difficulty", false, 2, []string{"Sun", "Glass"}},
+		{"downstreamReach", false, 1, []string{"Air"}},
+		{"importance", false, 2, []string{"Air", "Fire"}},
+	}
+	for _, test := range tests {
+		ranked, err := analytics.Rank(test.metric, test.ascending, test.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]string, len(ranked))
+		for i, stats := range ranked {
+			got[i] = stats.Name
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("Rank(%s, %v, %d) = %v, want %v", test.metric, test.ascending, test.limit, got, test.want)
+		}
+	}
+
+	if all, _ := analytics.Rank("tier", false, 0); len(all) != len(fixtureRecipes.Element) {
+		t.Errorf("Rank without a limit returned %d elements, want all %d", len(all), len(fixtureRecipes.Element))
+	}
+	if _, err := analytics.Rank("popularity", false, 0); err == nil {
+		t.Error("Rank accepted an unknown metric")
+	}
+}
+
+func TestMinimalRecipes(t *testing.T) {
+	graph := fixtureGraph(t)
+	minimal := MinimalRecipes(graph)
+
+	tests := []struct {
+		element string
+		size    int
+		recipe  []string // Nil for base elements
+	}{
+		{"Fire", 0, nil},
+		{"Mud", 1, []string{"Earth", "Water"}},
+		// Lava + Air and Mud + Fire are both 2 combinations, the first recipe wins
+		{"Stone", 2, []string{"Lava", "Air"}},
+		{"Acid rain", 6, []string{"Rain", "Stone"}},
+	}
+	for _, test := range tests {
+		got, ok := minimal[element(t, graph, test.element)]
+		if !ok {
+			t.Errorf("%s has no minimal recipe", test.element)
+			continue
+		}
+		var recipe []string
+		for _, ingredient := range got.Recipe {
+			recipe = append(recipe, ingredient.Name)
+		}
+		if got.Size != test.size || !slices.Equal(recipe, test.recipe) {
+			t.Errorf("%s = size %d from %v, want size %d from %v", test.element, got.Size, recipe, test.size, test.recipe)
+		}
+	}
+	for _, name := range []string{"Sun", "Glass"} {
+		if _, ok := minimal[element(t, graph, name)]; ok {
+			t.Errorf("%s has a minimal recipe, want it uncraftable", name)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package search
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Formats Export understands
+var ExportFormats = []string{"dot", "graphml", "gexf"}
+
+// A recipe as its own node, so a two ingredient combination can be drawn in an ordinary graph:
+// both ingredients point to the recipe node and the recipe node points to the result
+type recipeNode struct {
+	id          string
+	result      *ElementNode
+	ingredients []*ElementNode
+}
+
+func elementNodeID(element *ElementNode) string { return "e" + strconv.Itoa(element.ID) }
+
+func recipeNodes(graph *RecipeGraph) []recipeNode {
+	nodes := make([]recipeNode, 0)
+	for _, element := range graph.Elements[1:] {
+		for _, recipe := range element.Recipes {
+			// Base elements list the sentinel as their recipe
+			if len(recipe) != 2 || recipe[0].ID == 0 || recipe[1].ID == 0 {
+				continue
+			}
+			nodes = append(nodes, recipeNode{
+				id:          "r" + strconv.Itoa(len(nodes)+1),
+				result:      element,
+				ingredients: recipe,
+			})
+		}
+	}
+	return nodes
+}
+
+// Export writes the whole graph in one of ExportFormats
+func Export(graph *RecipeGraph, format string, w io.Writer) error {
+	switch strings.ToLower(format) {
+	case "dot":
+		return WriteDOT(graph, w)
+	case "graphml":
+		return WriteGraphML(graph, w)
+	case "gexf":
+		return WriteGEXF(graph, w)
+	}
+	return fmt.Errorf("unknown export format %s, use one of %s", format, strings.Join(ExportFormats, ", "))
+}
+
+// Graphviz: elements are boxes, recipes are small points between ingredients and result
+func WriteDOT(graph *RecipeGraph, w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph recipes {\n")
+	b.WriteString("\trankdir=LR;\n")
+	b.WriteString("\tnode [shape=box];\n")
+	for _, element := range graph.Elements[1:] {
+		fmt.Fprintf(&b, "\t%s [label=%s, tier=%d", elementNodeID(element), dotQuote(element.Name), element.Tier)
+		if element.Icon != "" {
+			fmt.Fprintf(&b, ", icon=%s", dotQuote(element.Icon))
+		}
+		b.WriteString("];\n")
+	}
+	for _, recipe := range recipeNodes(graph) {
+		fmt.Fprintf(&b, "\t%s [shape=point];\n", recipe.id)
+		for _, ingredient := range recipe.ingredients {
+			fmt.Fprintf(&b, "\t%s -> %s;\n", elementNodeID(ingredient), recipe.id)
+		}
+		fmt.Fprintf(&b, "\t%s -> %s;\n", recipe.id, elementNodeID(recipe.result))
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func dotQuote(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text) + `"`
+}
+
+/* ----------------------------------------- GraphML ----------------------------------------------- */
+
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+func WriteGraphML(graph *RecipeGraph, w io.Writer) error {
+	doc := graphMLDocument{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "kind", For: "node", Name: "kind", Type: "string"},
+			{ID: "label", For: "node", Name: "label", Type: "string"},
+			{ID: "tier", For: "node", Name: "tier", Type: "int"},
+			{ID: "icon", For: "node", Name: "icon", Type: "string"},
+		},
+		Graph: graphMLGraph{ID: "recipes", EdgeDefault: "directed"},
+	}
+	for _, element := range graph.Elements[1:] {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: elementNodeID(element),
+			Data: []graphMLData{
+				{Key: "kind", Value: "element"},
+				{Key: "label", Value: element.Name},
+				{Key: "tier", Value: strconv.Itoa(element.Tier)},
+				{Key: "icon", Value: element.Icon},
+			},
+		})
+	}
+	for _, recipe := range recipeNodes(graph) {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID:   recipe.id,
+			Data: []graphMLData{{Key: "kind", Value: "recipe"}},
+		})
+		for _, ingredient := range recipe.ingredients {
+			doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{Source: elementNodeID(ingredient), Target: recipe.id})
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{Source: recipe.id, Target: elementNodeID(recipe.result)})
+	}
+	return writeXML(w, doc)
+}
+
+/* ----------------------------------------- GEXF ----------------------------------------------- */
+
+type gexfDocument struct {
+	XMLName xml.Name  `xml:"gexf"`
+	XMLNS   string    `xml:"xmlns,attr"`
+	Version string    `xml:"version,attr"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+type gexfGraph struct {
+	DefaultEdgeType string         `xml:"defaultedgetype,attr"`
+	Attributes      gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode     `xml:"nodes>node"`
+	Edges           []gexfEdge     `xml:"edges>edge"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfNode struct {
+	ID     string          `xml:"id,attr"`
+	Label  string          `xml:"label,attr"`
+	Values []gexfAttrValue `xml:"attvalues>attvalue"`
+}
+
+type gexfAttrValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type gexfEdge struct {
+	ID     string `xml:"id,attr"`
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+}
+
+func WriteGEXF(graph *RecipeGraph, w io.Writer) error {
+	doc := gexfDocument{
+		XMLNS:   "http://gexf.net/1.3",
+		Version: "1.3",
+		Graph: gexfGraph{
+			DefaultEdgeType: "directed",
+			Attributes: gexfAttributes{
+				Class: "node",
+				Attributes: []gexfAttribute{
+					{ID: "kind", Title: "kind", Type: "string"},
+					{ID: "tier", Title: "tier", Type: "integer"},
+					{ID: "icon", Title: "icon", Type: "string"},
+				},
+			},
+		},
+	}
+	for _, element := range graph.Elements[1:] {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, gexfNode{
+			ID:    elementNodeID(element),
+			Label: element.Name,
+			Values: []gexfAttrValue{
+				{For: "kind", Value: "element"},
+				{For: "tier", Value: strconv.Itoa(element.Tier)},
+				{For: "icon", Value: element.Icon},
+			},
+		})
+	}
+	addEdge := func(source, target string) {
+		doc.Graph.Edges = append(doc.Graph.Edges, gexfEdge{
+			ID:     strconv.Itoa(len(doc.Graph.Edges)),
+			Source: source,
+			Target: target,
+		})
+	}
+	for _, recipe := range recipeNodes(graph) {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, gexfNode{
+			ID:     recipe.id,
+			Label:  recipe.ingredients[0].Name + " + " + recipe.ingredients[1].Name,
+			Values: []gexfAttrValue{{For: "kind", Value: "recipe"}},
+		})
+		for _, ingredient := range recipe.ingredients {
+			addEdge(elementNodeID(ingredient), recipe.id)
+		}
+		addEdge(recipe.id, elementNodeID(recipe.result))
+	}
+	return writeXML(w, doc)
+}
+
+func writeXML(w io.Writer, doc any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
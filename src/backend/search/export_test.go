@@ -0,0 +1,161 @@
+package search
+
+import (
+	"backend/scraping"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+// Names that need escaping in every format
+var exportRecipes = scraping.RecipeEntry{
+	Element: []string{"Air", "Earth", "Fire", "Water", `Rock "n" Roll`, "Salt & Pepper", `<Void>\`},
+	Recipe: map[string][][]string{
+		"Air":           {{"", ""}},
+		"Earth":         {{"", ""}},
+		"Fire":          {{"", ""}},
+		"Water":         {{"", ""}},
+		`Rock "n" Roll`: {{"Fire", "Earth"}},
+		"Salt & Pepper": {{"Water", "Air"}},
+		`<Void>\`:       {{`Rock "n" Roll`, "Salt & Pepper"}},
+	},
+	Tiering: map[string]int{"Air": 0, "Earth": 0, "Fire": 0, "Water": 0, `Rock "n" Roll`: 1, "Salt & Pepper": 1, `<Void>\`: 2},
+	Icon:    map[string]string{"Salt & Pepper": "icons/salt&pepper.svg"},
+}
+
+func exportGraph(t *testing.T) *RecipeGraph {
+	t.Helper()
+	var graph RecipeGraph
+	if err := ConstructRecipeGraph(exportRecipes, &graph); err != nil {
+		t.Fatal(err)
+	}
+	return &graph
+}
+
+func TestWriteDOT(t *testing.T) {
+	var out strings.Builder
+	if err := Export(exportGraph(t), "DOT", &out); err != nil {
+		t.Fatal(err)
+	}
+	dot := out.String()
+
+	for _, want := range []string{
+		"digraph recipes {\n",
+		"\te5 [label=\"Rock \\\"n\\\" Roll\", tier=1];\n",
+		"\te6 [label=\"Salt & Pepper\", tier=1, icon=\"icons/salt&pepper.svg\"];\n",
+		"\te7 [label=\"<Void>\\\\\", tier=2];\n",
+		"\tr3 [shape=point];\n\te5 -> r3;\n\te6 -> r3;\n\tr3 -> e7;\n",
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT lacks %q:\n%s", want, dot)
+		}
+	}
+	// Three recipes, each with two ingredient edges and one result edge
+	if edges := strings.Count(dot, " -> "); edges != 9 {
+		t.Errorf("DOT has %d edges, want 9", edges)
+	}
+	if !strings.HasSuffix(dot, "}\n") {
+		t.Error("DOT is not closed")
+	}
+}
+
+func TestWriteGraphML(t *testing.T) {
+	var out bytes.Buffer
+	if err := Export(exportGraph(t), "graphml", &out); err != nil {
+		t.Fatal(err)
+	}
+	wellFormed(t, out.Bytes())
+
+	var doc graphMLDocument
+	if err := xml.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Graph.Nodes) != 10 || len(doc.Graph.Edges) != 9 {
+		t.Fatalf("%d nodes and %d edges, want 7 elements plus 3 recipes and 9 edges", len(doc.Graph.Nodes), len(doc.Graph.Edges))
+	}
+
+	labels := make(map[string]string)
+	ids := make(map[string]bool)
+	for _, node := range doc.Graph.Nodes {
+		ids[node.ID] = true
+		for _, data := range node.Data {
+			if data.Key == "label" {
+				labels[node.ID] = data.Value
+			}
+		}
+	}
+	for i, name := range exportRecipes.Element {
+		if id := elementNodeID(&ElementNode{ID: i + 1}); labels[id] != name {
+			t.Errorf("%s is labelled %q, want %q", id, labels[id], name)
+		}
+	}
+	for _, edge := range doc.Graph.Edges {
+		if !ids[edge.Source] || !ids[edge.Target] {
+			t.Errorf("edge %s -> %s points outside the graph", edge.Source, edge.Target)
+		}
+	}
+}
+
+func TestWriteGEXF(t *testing.T) {
+	var out bytes.Buffer
+	if err := Export(exportGraph(t), "gexf", &out); err != nil {
+		t.Fatal(err)
+	}
+	wellFormed(t, out.Bytes())
+
+	var doc gexfDocument
+	if err := xml.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Graph.Nodes) != 10 || len(doc.Graph.Edges) != 9 {
+		t.Fatalf("%d nodes and %d edges, want 7 elements plus 3 recipes and 9 edges", len(doc.Graph.Nodes), len(doc.Graph.Edges))
+	}
+
+	labels := make(map[string]bool)
+	ids := make(map[string]bool)
+	for _, node := range doc.Graph.Nodes {
+		labels[node.Label] = true
+		ids[node.ID] = true
+	}
+	for _, want := range append(exportRecipes.Element, `Rock "n" Roll + Salt & Pepper`) {
+		if !labels[want] {
+			t.Errorf("no node is labelled %q", want)
+		}
+	}
+	edgeIDs := make(map[string]bool)
+	for _, edge := range doc.Graph.Edges {
+		if !ids[edge.Source] || !ids[edge.Target] {
+			t.Errorf("edge %s -> %s points outside the graph", edge.Source, edge.Target)
+		}
+		if edgeIDs[edge.ID] {
+			t.Errorf("edge id %s is used twice", edge.ID)
+		}
+		edgeIDs[edge.ID] = true
+	}
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	if err := Export(exportGraph(t), "csv", io.Discard); err == nil {
+		t.Error("Export accepted csv")
+	}
+}
+
+// Reads every token, so unescaped markup in a name fails here
+func wellFormed(t *testing.T, document []byte) {
+	t.Helper()
+	if !bytes.HasPrefix(document, []byte(xml.Header)) {
+		t.Error("the XML header is missing")
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(document))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatalf("not well-formed XML: %v", err)
+		}
+	}
+}
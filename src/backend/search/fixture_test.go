@@ -0,0 +1,61 @@
+package search
+
+import (
+	"backend/scraping"
+	"testing"
+)
+
+// The algorithm package fixture plus two elements without a recipe tree: Sun's only recipe
+// names an element the dataset lacks, Glass only has a recipe of a higher tier ingredient
+var fixtureRecipes = scraping.RecipeEntry{
+	Element: []string{
+		"Air", "Earth", "Fire", "Water",
+		"Steam", "Lava", "Dust", "Mud", "Energy", "Pressure",
+		"Stone", "Cloud", "Rain", "Metal", "Acid rain", "Island",
+		"Sun", "Glass",
+	},
+	Recipe: map[string][][]string{
+		"Air":       {{"", ""}},
+		"Earth":     {{"", ""}},
+		"Fire":      {{"", ""}},
+		"Water":     {{"", ""}},
+		"Steam":     {{"Fire", "Water"}, {"Water", "Fire"}, {"Air", "Water"}},
+		"Lava":      {{"Earth", "Fire"}},
+		"Dust":      {{"Earth", "Air"}},
+		"Mud":       {{"Earth", "Water"}, {"Dust", "Water"}},
+		"Energy":    {{"Fire", "Air"}, {"Fire", "Fire"}},
+		"Pressure":  {{"Air", "Air"}, {"Earth", "Earth"}},
+		"Stone":     {{"Lava", "Air"}, {"Lava", "Pressure"}, {"Mud", "Fire"}},
+		"Cloud":     {{"Steam", "Air"}, {"Steam", "Pressure"}},
+		"Rain":      {{"Cloud", "Water"}, {"Cloud", "Steam"}},
+		"Metal":     {{"Stone", "Fire"}, {"Stone", "Energy"}},
+		"Acid rain": {{"Rain", "Metal"}, {"Rain", "Stone"}},
+		"Island":    {{"Stone", "Water"}, {"Water", "Stone"}},
+		"Sun":       {{"Sky", "Fire"}},
+		"Glass":     {{"Stone", "Fire"}},
+	},
+	Tiering: map[string]int{
+		"Air": 0, "Earth": 0, "Fire": 0, "Water": 0,
+		"Steam": 1, "Lava": 1, "Dust": 1, "Energy": 1, "Pressure": 1,
+		"Mud": 2, "Cloud": 2, "Stone": 3, "Rain": 3, "Metal": 4, "Island": 4, "Acid rain": 5,
+		"Sun": 6, "Glass": 2,
+	},
+}
+
+func fixtureGraph(t *testing.T) *RecipeGraph {
+	t.Helper()
+	var graph RecipeGraph
+	if err := ConstructRecipeGraph(fixtureRecipes, &graph); err != nil {
+		t.Fatal(err)
+	}
+	return &graph
+}
+
+func element(t *testing.T, graph *RecipeGraph, name string) *ElementNode {
+	t.Helper()
+	node, err := GetElementByName(graph, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return node
+}
@@ -15,13 +15,21 @@ type ElementNode struct {
 	Tier     int              // Tier 1-15. Base elements is tier 0
 	Children []*ElementNode   // List of elements that can be created from this element
 	Recipes  [][]*ElementNode // Parents. List of pairs of elements that can be combined to create this element
+	Icon     string           // Path of the downloaded icon, empty when icons were not scraped
 }
 
 // Set of all elements
 // The graph is a directed graph
 type RecipeGraph struct {
-	Elements     []*ElementNode
-	BaseElements []*ElementNode // Air, Earth, Fire, Water
+	Elements       []*ElementNode
+	BaseElements   []*ElementNode  // Air, Earth, Fire, Water
+	SkippedRecipes []SkippedRecipe // Recipes left out because an ingredient is not an element
+}
+
+// A scraped recipe that could not be added to the graph
+type SkippedRecipe struct {
+	Element     string   `json:"element"`
+	Ingredients []string `json:"ingredients"`
 }
 
 func GetRoot(graph *RecipeGraph) *ElementNode          { return graph.Elements[0] }
@@ -35,6 +43,7 @@ func ConstructRecipeGraph(recipesJSON scraping.RecipeEntry, graph *RecipeGraph)
 	elementMap := make(map[string]*ElementNode)
 
 	graph.Elements = make([]*ElementNode, 1+len(recipesJSON.Element))
+	graph.SkippedRecipes = make([]SkippedRecipe, 0)
 
 	// Sentinel element for primordial elements
 	sentinelElement := ElementNode{
@@ -59,6 +68,7 @@ func ConstructRecipeGraph(recipesJSON scraping.RecipeEntry, graph *RecipeGraph)
 		} else {
 			node.Tier = 0 // Default tier for elements without a specified tier
 		}
+		node.Icon = recipesJSON.Icon[elementName]
 		graph.Elements[i+1] = &node
 		elementMap[elementName] = &node
 	}
@@ -71,6 +81,7 @@ func ConstructRecipeGraph(recipesJSON scraping.RecipeEntry, graph *RecipeGraph)
 			parent2, ok2 := elementMap[recipe[1]]
 			if !ok1 || !ok2 {
 				fmt.Printf("Skipping recipe for element %s: missing parent(s) %v\n", elementName, recipe)
+				graph.SkippedRecipes = append(graph.SkippedRecipes, SkippedRecipe{Element: elementName, Ingredients: recipe})
 				continue
 			}
 			node.Recipes = append(node.Recipes, []*ElementNode{parent1, parent2})
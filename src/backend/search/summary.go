@@ -0,0 +1,83 @@
+package search
+
+import (
+	"math"
+	"sort"
+)
+
+// Elements and recipes of one tier. Recipes are counted on the element they create
+type TierStats struct {
+	Tier     int `json:"tier"`
+	Elements int `json:"elements"`
+	Recipes  int `json:"recipes"`
+}
+
+// An element together with how many edges it has on one side
+type ElementCount struct {
+	Element string `json:"element"`
+	Count   int    `json:"count"`
+}
+
+// Summary of a whole loaded graph, used to spot problems with a scraped dataset
+type GraphStats struct {
+	Elements       int             `json:"elements"`
+	Recipes        int             `json:"recipes"`
+	Tiers          []TierStats     `json:"tiers"`
+	DeadEnds       int             `json:"deadEnds"`       // Elements no recipe uses
+	Unreachable    []string        `json:"unreachable"`    // Elements without a recipe tree under tier pruning
+	AverageRecipes float64         `json:"averageRecipes"` // Recipes per non-base element
+	LargestFanIn   ElementCount    `json:"largestFanIn"`   // Most recipes
+	LargestFanOut  ElementCount    `json:"largestFanOut"`  // Most children
+	SkippedRecipes []SkippedRecipe `json:"skippedRecipes"`
+}
+
+func Summarize(graph *RecipeGraph) GraphStats {
+	elements := graph.Elements[1:]
+	stats := GraphStats{
+		Elements:       len(elements),
+		Tiers:          make([]TierStats, 0),
+		Unreachable:    make([]string, 0),
+		SkippedRecipes: graph.SkippedRecipes,
+	}
+	if stats.SkippedRecipes == nil {
+		stats.SkippedRecipes = make([]SkippedRecipe, 0)
+	}
+
+	byTier := make(map[int]*TierStats)
+	minimal := MinimalRecipes(graph)
+	for _, element := range elements {
+		recipes := recipeCount(element)
+		stats.Recipes += recipes
+
+		tier, ok := byTier[element.Tier]
+		if !ok {
+			tier = &TierStats{Tier: element.Tier}
+			byTier[element.Tier] = tier
+		}
+		tier.Elements++
+		tier.Recipes += recipes
+
+		if len(element.Children) == 0 {
+			stats.DeadEnds++
+		}
+		if _, craftable := minimal[element]; !craftable {
+			stats.Unreachable = append(stats.Unreachable, element.Name)
+		}
+		if recipes > stats.LargestFanIn.Count {
+			stats.LargestFanIn = ElementCount{Element: element.Name, Count: recipes}
+		}
+		if len(element.Children) > stats.LargestFanOut.Count {
+			stats.LargestFanOut = ElementCount{Element: element.Name, Count: len(element.Children)}
+		}
+	}
+
+	for _, tier := range byTier {
+		stats.Tiers = append(stats.Tiers, *tier)
+	}
+	sort.Slice(stats.Tiers, func(i, j int) bool { return stats.Tiers[i].Tier < stats.Tiers[j].Tier })
+
+	if crafted := len(elements) - len(graph.BaseElements); crafted > 0 {
+		stats.AverageRecipes = math.Round(float64(stats.Recipes)/float64(crafted)*100) / 100
+	}
+	return stats
+}
@@ -0,0 +1,52 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	got := Summarize(fixtureGraph(t))
+
+	want := GraphStats{
+		Elements: 18,
+		Recipes:  25,
+		Tiers: []TierStats{
+			{Tier: 0, Elements: 4, Recipes: 0},
+			{Tier: 1, Elements: 5, Recipes: 9},
+			{Tier: 2, Elements: 3, Recipes: 5},
+			{Tier: 3, Elements: 2, Recipes: 5},
+			{Tier: 4, Elements: 2, Recipes: 4},
+			{Tier: 5, Elements: 1, Recipes: 2},
+			{Tier: 6, Elements: 1, Recipes: 0},
+		},
+		DeadEnds:       4, // Acid rain, Island, Sun and Glass
+		Unreachable:    []string{"Sun", "Glass"},
+		AverageRecipes: 1.79, // 25 recipes over 14 non-base elements
+		// Ties keep the first element in graph order
+		LargestFanIn:   ElementCount{Element: "Steam", Count: 3},
+		LargestFanOut:  ElementCount{Element: "Air", Count: 6},
+		SkippedRecipes: []SkippedRecipe{{Element: "Sun", Ingredients: []string{"Sky", "Fire"}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestSummarizeWithoutProblems(t *testing.T) {
+	recipes := fixtureRecipes
+	recipes.Element = recipes.Element[:4]
+	var graph RecipeGraph
+	if err := ConstructRecipeGraph(recipes, &graph); err != nil {
+		t.Fatal(err)
+	}
+
+	got := Summarize(&graph)
+	// Empty lists rather than null in the JSON answer
+	if got.Unreachable == nil || got.SkippedRecipes == nil || len(got.Unreachable) != 0 || len(got.SkippedRecipes) != 0 {
+		t.Errorf("unreachable %v and skipped %v, want both empty", got.Unreachable, got.SkippedRecipes)
+	}
+	if got.Elements != 4 || got.Recipes != 0 || got.DeadEnds != 4 || got.AverageRecipes != 0 {
+		t.Errorf("got %+v for the base elements alone", got)
+	}
+}
@@ -0,0 +1,74 @@
+package search
+
+import (
+	"slices"
+	"sort"
+)
+
+// UsableRecipe reports whether a recipe may appear in a recipe tree: both ingredients must be
+// of a lower tier than the result. Every search applies this rule, it keeps every tree finite
+func UsableRecipe(element *ElementNode, recipe []*ElementNode) bool {
+	return len(recipe) == 2 && recipe[0].Tier < element.Tier && recipe[1].Tier < element.Tier
+}
+
+// TierFold computes a value for every element that can be crafted from the base elements
+// through usable recipes. Usable recipes only combine lower tiers, so visiting elements in
+// tier order settles both ingredients before the elements they make and one pass is enough
+type TierFold[V any] struct {
+	Skip func(element *ElementNode) bool // Optional, skipped elements count as uncraftable
+	Base func(element *ElementNode) V
+	// Value of element through one usable recipe whose ingredients both have a value
+	Recipe func(element *ElementNode, recipe []*ElementNode, left, right V) V
+	// Optional, whether value replaces the one an earlier recipe gave. Without it the first recipe is kept
+	Better func(value, current V) bool
+}
+
+// Run folds the graph. Elements missing from the result cannot be crafted
+func (fold TierFold[V]) Run(graph *RecipeGraph) map[*ElementNode]V {
+	ordered := slices.Clone(graph.Elements[1:])
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Tier < ordered[j].Tier })
+
+	values := make(map[*ElementNode]V)
+	for _, element := range ordered {
+		if fold.Skip != nil && fold.Skip(element) {
+			continue
+		}
+		if slices.Contains(graph.BaseElements, element) {
+			values[element] = fold.Base(element)
+			continue
+		}
+		for _, recipe := range element.Recipes {
+			if !UsableRecipe(element, recipe) {
+				continue
+			}
+			left, leftOk := values[recipe[0]]
+			right, rightOk := values[recipe[1]]
+			if !leftOk || !rightOk {
+				continue
+			}
+			value := fold.Recipe(element, recipe, left, right)
+			if current, seen := values[element]; !seen || (fold.Better != nil && fold.Better(value, current)) {
+				values[element] = value
+			}
+		}
+	}
+	return values
+}
+
+// Fewest combinations to craft an element and the recipe that achieves it
+type MinimalRecipe struct {
+	Size   int
+	Recipe []*ElementNode // Nil for base elements
+}
+
+// MinimalRecipes finds the smallest recipe tree of every craftable element, the first
+// recipe in graph order wins ties
+func MinimalRecipes(graph *RecipeGraph) map[*ElementNode]MinimalRecipe {
+	return TierFold[MinimalRecipe]{
+		Base: func(*ElementNode) MinimalRecipe { return MinimalRecipe{} },
+		Recipe: func(_ *ElementNode, recipe []*ElementNode, left, right MinimalRecipe) MinimalRecipe {
+			return MinimalRecipe{Size: 1 + left.Size + right.Size, Recipe: recipe}
+		},
+		Better: func(value, current MinimalRecipe) bool { return value.Size < current.Size },
+	}.Run(graph)
+}
@@ -0,0 +1,194 @@
+package server
+
+import (
+	"backend/algorithm"
+	"backend/search"
+	"encoding/json"
+	"log"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limits of one batch: items per request and searches running at the same time
+const (
+	maxBatchItems = 100
+	batchWorkers  = 4
+)
+
+type batchItem struct {
+	Element string   `json:"element"`
+	Algo    string   `json:"algo"`    // bfs by default
+	Max     int      `json:"max"`     // 1 by default
+	Include []string `json:"include"` // Elements every tree must contain
+	Exclude []string `json:"exclude"` // Elements no tree may contain
+}
+
+// POST http://localhost:8080/api/recipes/batch [{"element": "Acid rain", "algo": "bfs", "max": 3}, ...]
+// The body may also be {"requests": [...]}. Every item is searched like /api/recipes, a few at a time,
+// and answered at the same index with its own status, so one failing item does not fail the others
+func searchBatch(s *searcher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		items, err := parseBatch(c)
+		if err != nil {
+			writeSearchError(c, err)
+			return
+		}
+
+		results := make([]gin.H, len(items))
+		ok := runBatch(c, len(items), func(i int) {
+			item := items[i]
+			result, err := runBatchItem(s, item)
+			if err != nil {
+				status, body := searchErrorBody(err)
+				body["status"] = status
+				body["element"] = item.Element
+				body["algo"] = item.Algo
+				results[i] = body
+			} else {
+				results[i] = gin.H{
+					"status":  http.StatusOK,
+					"error":   false,
+					"element": item.Element,
+					"algo":    result.Algo,
+					"data": gin.H{
+						"trees":        result.Trees,
+						"stats":        result.Stats,
+						"visitedNodes": result.Stats.NodesExpanded,
+					},
+				}
+			}
+			results[i]["index"] = i
+		})
+		if !ok {
+			return
+		}
+
+		failed := 0
+		for _, result := range results {
+			if result["error"] == true {
+				failed++
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"error": false,
+			"data": gin.H{
+				"results":   results,
+				"succeeded": len(results) - failed,
+				"failed":    failed,
+			},
+		})
+	}
+}
+
+// Accepts a bare list or {"requests": [...]}, with defaults filled in
+func parseBatch(c *gin.Context) ([]batchItem, error) {
+	var raw json.RawMessage
+	if err := c.ShouldBindJSON(&raw); err != nil {
+		return nil, newAPIError(http.StatusBadRequest, errInvalidParameter, "Body must be a list of {element, algo, max} requests: %s", err.Error())
+	}
+	var items []batchItem
+	if err := json.Unmarshal(raw, &items); err != nil {
+		var wrapped struct {
+			Requests []batchItem `json:"requests"`
+		}
+		if err := json.Unmarshal(raw, &wrapped); err != nil {
+			return nil, newAPIError(http.StatusBadRequest, errInvalidParameter, "Body must be a list of {element, algo, max} requests: %s", err.Error())
+		}
+		items = wrapped.Requests
+	}
+	if len(items) == 0 || len(items) > maxBatchItems {
+		return nil, newAPIError(http.StatusBadRequest, errInvalidParameter, "A batch must hold between 1 and %d requests", maxBatchItems)
+	}
+
+	for i := range items {
+		items[i].Algo = strings.ToLower(items[i].Algo)
+		if items[i].Algo == "" {
+			items[i].Algo = "bfs"
+		}
+		if items[i].Max == 0 {
+			items[i].Max = 1
+		}
+	}
+	return items, nil
+}
+
+// Calls search for every index on a bounded pool of goroutines and waits for them.
+// Returns false when the client went away before every index was handed out
+func runBatch(c *gin.Context, count int, search func(i int)) bool {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(batchWorkers, runtime.GOMAXPROCS(0), count) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				search(i)
+			}
+		}()
+	}
+
+	done := c.Request.Context().Done()
+send:
+	for i := range count {
+		select {
+		case jobs <- i:
+		case <-done:
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return c.Request.Context().Err() == nil
+}
+
+// One item searched the way the single endpoints would, sharing their cache.
+// It runs on a batch worker, out of reach of gin's recovery, so a panic only fails this item
+func runBatchItem(s *searcher, item batchItem) (result algorithm.SearchResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("batch search for %q panicked: %v\n%s", item.Element, r, debug.Stack())
+			result, err = algorithm.SearchResult{}, newAPIError(http.StatusInternalServerError, errSearchFailed, "The search for '%s' failed", item.Element)
+		}
+	}()
+	if item.Element == "" {
+		return algorithm.SearchResult{}, newAPIError(http.StatusBadRequest, errMissingParameter, "Element is required")
+	}
+	if item.Max < 0 || item.Max > maxTrees {
+		return algorithm.SearchResult{}, newAPIError(http.StatusBadRequest, errInvalidParameter, "Max must be between 1 and %d", maxTrees)
+	}
+	node, err := search.GetElementByName(s.graph, item.Element)
+	if err != nil {
+		return algorithm.SearchResult{}, elementNotFound(item.Element)
+	}
+
+	var constraints algorithm.Constraints
+	lists := map[string]struct {
+		names []string
+		into  *[]*search.ElementNode
+	}{
+		"include": {item.Include, &constraints.Include},
+		"exclude": {item.Exclude, &constraints.Exclude},
+	}
+	for field, list := range lists {
+		for _, name := range list.names {
+			element, err := search.GetElementByName(s.graph, name)
+			if err != nil {
+				return algorithm.SearchResult{}, newAPIError(http.StatusNotFound, errElementNotFound, "Element '%s' in %s not found", name, field)
+			}
+			*list.into = append(*list.into, element)
+		}
+	}
+
+	outcome, err := s.run(item.Algo, algorithm.SearchRequest{
+		Target:      node,
+		Graph:       s.graph,
+		MaxPaths:    item.Max,
+		Constraints: constraints,
+	})
+	return outcome.SearchResult, err
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func postJSON(t *testing.T, router http.Handler, url, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestBatchAnswersEveryItem(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := New(testGraph(t), Config{})
+
+	recorder := postJSON(t, router, "/api/recipes/batch", `[
+		{"element": "Metal", "max": 2},
+		{"element": "Gold"},
+		{"element": ""},
+		{"element": "Stone", "algo": "quantum"},
+		{"element": "Metal", "algo": "dfs", "max": 9223372036854775807},
+		{"element": "Stone", "exclude": ["Lava", "Mud"]}
+	]`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", recorder.Code, recorder.Body)
+	}
+	var body struct {
+		Data struct {
+			Results []struct {
+				Index   int    `json:"index"`
+				Status  int    `json:"status"`
+				Error   bool   `json:"error"`
+				Type    string `json:"type"`
+				Element string `json:"element"`
+				Data    struct {
+					Trees []any `json:"trees"`
+				} `json:"data"`
+			} `json:"results"`
+			Succeeded int `json:"succeeded"`
+			Failed    int `json:"failed"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		status  int
+		errType string
+	}{
+		{http.StatusOK, ""},
+		{http.StatusNotFound, errElementNotFound},
+		{http.StatusBadRequest, errMissingParameter},
+		{http.StatusBadRequest, errInvalidAlgorithm},
+		{http.StatusBadRequest, errInvalidParameter},
+		{http.StatusNotFound, errUnreachable},
+	}
+	if len(body.Data.Results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(body.Data.Results), len(want))
+	}
+	for i, result := range body.Data.Results {
+		if result.Index != i || result.Status != want[i].status || result.Type != want[i].errType || result.Error != (want[i].errType != "") {
+			t.Errorf("result %d = %+v, want status %d and type %q", i, result, want[i].status, want[i].errType)
+		}
+	}
+	if got := len(body.Data.Results[0].Data.Trees); got != 2 {
+		t.Errorf("Metal got %d trees, want 2", got)
+	}
+	if body.Data.Succeeded != 1 || body.Data.Failed != 5 {
+		t.Errorf("succeeded %d and failed %d, want 1 and 5", body.Data.Succeeded, body.Data.Failed)
+	}
+
+	// The oversized max did not take the server down
+	if recorder := serve(t, router, "/api/recipe?element=Metal&algo=dfs"); recorder.Code != http.StatusOK {
+		t.Errorf("server answers %d after the batch", recorder.Code)
+	}
+}
+
+func TestBatchRejectsUnusableBodies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := New(testGraph(t), Config{})
+
+	bodies := map[string]string{
+		"not json":      `element=Metal`,
+		"empty list":    `[]`,
+		"wrong shape":   `{"requests": 5}`,
+		"wrong item":    `[{"element": 5}]`,
+		"too many":      "[" + strings.Repeat(`{"element": "Metal"},`, maxBatchItems) + `{"element": "Metal"}]`,
+		"empty wrapper": `{"requests": []}`,
+	}
+	for name, body := range bodies {
+		recorder := postJSON(t, router, "/api/recipes/batch", body)
+		var answer errorResponse
+		json.Unmarshal(recorder.Body.Bytes(), &answer)
+		if recorder.Code != http.StatusBadRequest || answer.Type != errInvalidParameter {
+			t.Errorf("%s: got %d %q, want 400 %s", name, recorder.Code, answer.Type, errInvalidParameter)
+		}
+
+		recorder = postJSON(t, router, "/api/v2/recipes/batch", body)
+		var wrapped envelope
+		json.Unmarshal(recorder.Body.Bytes(), &wrapped)
+		if recorder.Code != http.StatusBadRequest || wrapped.Error == nil || wrapped.Error.Type != errInvalidParameter {
+			t.Errorf("v2 %s: got %d %s", name, recorder.Code, recorder.Body)
+		}
+	}
+}
+
+func TestBatchV2ItemsAreEnvelopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := New(testGraph(t), Config{})
+
+	recorder := postJSON(t, router, "/api/v2/recipes/batch", `{"requests": [{"element": "Stone", "max": 2}, {"element": "Stone", "max": 1001}]}`)
+	var body struct {
+		Data struct {
+			Results []struct {
+				Data  *recipesData `json:"data"`
+				Error *apiError    `json:"error"`
+			} `json:"results"`
+			Succeeded int `json:"succeeded"`
+			Failed    int `json:"failed"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil || recorder.Code != http.StatusOK {
+		t.Fatalf("got %d %s: %v", recorder.Code, recorder.Body, err)
+	}
+	results := body.Data.Results
+	if len(results) != 2 || results[0].Error != nil || len(results[0].Data.Trees) != 2 {
+		t.Fatalf("first result = %+v, want two Stone trees", results)
+	}
+	if results[1].Data != nil || results[1].Error == nil || results[1].Error.Type != errInvalidParameter {
+		t.Errorf("second result = %+v, want an invalid_parameter error", results[1])
+	}
+	if body.Data.Succeeded != 1 || body.Data.Failed != 1 {
+		t.Errorf("succeeded %d and failed %d, want 1 and 1", body.Data.Succeeded, body.Data.Failed)
+	}
+}
@@ -0,0 +1,280 @@
+package server
+
+import (
+	"backend/algorithm"
+	"backend/search"
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Defaults of Config.CacheEntries and Config.CacheBytes
+const (
+	defaultCacheEntries = 512
+	defaultCacheBytes   = 64 << 20
+)
+
+// How long clients may reuse a cached answer before revalidating it with its ETag.
+// Short, since a reload can change every answer
+const cacheMaxAge = 60
+
+// LRU of search results bounded by both entry count and approximate memory.
+// The graph only changes on a reload, which purges everything
+type resultCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int64
+	bytes      int64
+	order      *list.List // Of *cacheEntry, most recently used first
+	entries    map[string]*list.Element
+	generation uint64 // Bumped by every purge
+	hits       uint64
+	misses     uint64
+	evictions  uint64
+}
+
+type cacheEntry struct {
+	key    string
+	result algorithm.SearchResult
+	size   int64
+	tag    string // Hash of the result, so an ETag survives a restart only while the answer does
+}
+
+type CacheStats struct {
+	Hits       uint64 `json:"hits"`
+	Misses     uint64 `json:"misses"`
+	Evictions  uint64 `json:"evictions"`
+	Entries    int    `json:"entries"`
+	Bytes      int64  `json:"bytes" doc:"Approximate memory of the cached results, measured as their JSON size"`
+	MaxEntries int    `json:"maxEntries"`
+	MaxBytes   int64  `json:"maxBytes"`
+	Generation uint64 `json:"generation" doc:"Number of times the cache was purged by a reload"`
+}
+
+// Returns nil when entries is negative, a nil cache misses every lookup and stores nothing
+func newResultCache(entries int, bytes int64) *resultCache {
+	if entries < 0 {
+		return nil
+	}
+	if entries == 0 {
+		entries = defaultCacheEntries
+	}
+	if bytes <= 0 {
+		bytes = defaultCacheBytes
+	}
+	return &resultCache{
+		maxEntries: entries,
+		maxBytes:   bytes,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Element and option names come from the graph nodes, so differently cased or ordered
+// queries for the same search share one key
+func cacheKey(algo string, req algorithm.SearchRequest) string {
+	names := func(nodes []*search.ElementNode) string {
+		list := make([]string, len(nodes))
+		for i, node := range nodes {
+			list[i] = node.Name
+		}
+		slices.Sort(list)
+		return strings.Join(slices.Compact(list), ",")
+	}
+	return strings.Join([]string{
+		strings.ToLower(algo),
+		req.Target.Name,
+		strconv.Itoa(req.MaxPaths),
+		names(req.Constraints.Include),
+		names(req.Constraints.Exclude),
+	}, "\x00")
+}
+
+func (cache *resultCache) get(key string) (*cacheEntry, bool) {
+	if cache == nil {
+		return nil, false
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	element, ok := cache.entries[key]
+	if !ok {
+		cache.misses++
+		return nil, false
+	}
+	cache.hits++
+	cache.order.MoveToFront(element)
+	return element.Value.(*cacheEntry), true
+}
+
+// Stores a result and evicts the least recently used ones until both bounds hold.
+// A result larger than the whole memory bound is not kept
+func (cache *resultCache) put(key string, result algorithm.SearchResult) *cacheEntry {
+	size, tag := measureResult(result)
+	if cache == nil || size > cache.maxBytes {
+		return nil
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if element, ok := cache.entries[key]; ok {
+		// Another request searched the same thing meanwhile, keep the first answer
+		cache.order.MoveToFront(element)
+		return element.Value.(*cacheEntry)
+	}
+
+	entry := &cacheEntry{
+		key:    key,
+		result: result,
+		size:   size,
+		tag:    tag,
+	}
+	cache.entries[key] = cache.order.PushFront(entry)
+	cache.bytes += size
+	for cache.order.Len() > cache.maxEntries || cache.bytes > cache.maxBytes {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+		evicted := oldest.Value.(*cacheEntry)
+		delete(cache.entries, evicted.key)
+		cache.bytes -= evicted.size
+		cache.evictions++
+	}
+	return entry
+}
+
+// Drops every entry, called when the dataset is reloaded
+func (cache *resultCache) purge() {
+	if cache == nil {
+		return
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.order.Init()
+	clear(cache.entries)
+	cache.bytes = 0
+	cache.generation++
+}
+
+func (cache *resultCache) stats() CacheStats {
+	if cache == nil {
+		return CacheStats{}
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	return CacheStats{
+		Hits:       cache.hits,
+		Misses:     cache.misses,
+		Evictions:  cache.evictions,
+		Entries:    cache.order.Len(),
+		Bytes:      cache.bytes,
+		MaxEntries: cache.maxEntries,
+		MaxBytes:   cache.maxBytes,
+		Generation: cache.generation,
+	}
+}
+
+// Trees share subtrees in memory but are answered expanded, so the encoded size is the
+// upper bound of what keeping them costs. The tag hashes the whole encoded result, stats
+// included: equal tags mean an equal answer, whichever process or dataset produced it
+func measureResult(result algorithm.SearchResult) (size int64, tag string) {
+	trees, err := json.Marshal(result.Trees)
+	if err != nil {
+		return 0, ""
+	}
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return 0, ""
+	}
+	hash := fnv.New64a()
+	hash.Write(encoded)
+	return int64(len(trees)), fmt.Sprintf("%x", hash.Sum64())
+}
+
+/* ----------------------------------------- Searching ----------------------------------------------- */
+
+// What the search handlers share: the graph and the answers already known over it
+type searcher struct {
+	graph *search.RecipeGraph
+	cache *resultCache
+	index *recipeIndex // Nil unless Config.Index is set
+}
+
+// Where a search answer came from, sent as the X-Cache header
+const (
+	fromSearch = "MISS"
+	fromCache  = "HIT"
+	fromIndex  = "INDEX"
+)
+
+// A search result and where it came from
+type searchOutcome struct {
+	algorithm.SearchResult
+	tag    string // Identifies the stored result for ETags, empty when it was not stored
+	source string
+}
+
+// Answers a search from the index or the cache, or runs it. Failed searches are not cached
+func (s *searcher) run(algo string, req algorithm.SearchRequest) (searchOutcome, error) {
+	if result, ok := s.index.lookup(algo, req); ok {
+		return searchOutcome{SearchResult: result, tag: "i" + s.index.Dataset, source: fromIndex}, nil
+	}
+	key := cacheKey(algo, req)
+	if entry, ok := s.cache.get(key); ok {
+		return searchOutcome{SearchResult: entry.result, tag: entry.tag, source: fromCache}, nil
+	}
+	result, err := algorithm.Run(algo, req)
+	if err != nil {
+		return searchOutcome{}, err
+	}
+	outcome := searchOutcome{SearchResult: result, source: fromSearch}
+	if entry := s.cache.put(key, result); entry != nil {
+		outcome.tag = entry.tag
+	}
+	return outcome, nil
+}
+
+// Sets the caching headers of a search answer. Returns true, after answering 304,
+// when the client already holds this exact response
+func writeCacheHeaders(c *gin.Context, outcome searchOutcome) bool {
+	c.Header("X-Cache", outcome.source)
+	if outcome.tag == "" {
+		c.Header("Cache-Control", "no-store")
+		return false
+	}
+
+	// The same result is answered differently per route and query
+	hash := fnv.New64a()
+	hash.Write([]byte(outcome.tag + "\x00" + c.Request.URL.Path + "\x00" + c.Request.URL.RawQuery))
+	etag := fmt.Sprintf(`"%s-%x"`, outcome.tag, hash.Sum64())
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", cacheMaxAge))
+	if match := c.GetHeader("If-None-Match"); match != "" && (match == "*" || slices.Contains(splitETags(match), etag)) {
+		c.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+func splitETags(header string) []string {
+	tags := strings.Split(header, ",")
+	for i, tag := range tags {
+		tags[i] = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+	}
+	return tags
+}
+
+// http://localhost:8080/api/cache/stats
+func cacheStats(cache *resultCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"error": false,
+			"data":  cache.stats(),
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"backend/algorithm"
+	"backend/search"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	graph := testGraph(t)
+	request := func(name string) algorithm.SearchRequest {
+		node, err := search.GetElementByName(graph, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return algorithm.SearchRequest{Target: node, Graph: graph, MaxPaths: 1}
+	}
+	s := &searcher{graph: graph, cache: newResultCache(2, 0)}
+	for _, name := range []string{"Lava", "Mud", "Lava", "Stone"} {
+		if _, err := s.run("bfs", request(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Mud was used longest ago when Stone needed room
+	if outcome, _ := s.run("BFS", request("Lava")); outcome.source != fromCache {
+		t.Error("Lava was evicted, want it kept")
+	}
+	if outcome, _ := s.run("bfs", request("Mud")); outcome.source == fromCache {
+		t.Error("Mud was kept, want it evicted")
+	}
+	if stats := s.cache.stats(); stats.Hits != 2 || stats.Evictions != 2 || stats.Entries != 2 {
+		t.Errorf("stats = %+v, want 2 hits, 2 evictions and 2 entries", stats)
+	}
+
+	// Nothing fits in a single byte
+	s.cache = newResultCache(0, 1)
+	s.run("bfs", request("Stone"))
+	if outcome, _ := s.run("bfs", request("Stone")); outcome.source == fromCache || outcome.tag != "" {
+		t.Errorf("a result over the memory bound was cached")
+	}
+}
+
+func TestCacheKeyIgnoresOptionOrder(t *testing.T) {
+	graph := testGraph(t)
+	nodes := func(names ...string) []*search.ElementNode {
+		list := make([]*search.ElementNode, len(names))
+		for i, name := range names {
+			list[i], _ = search.GetElementByName(graph, name)
+		}
+		return list
+	}
+	request := func(exclude []*search.ElementNode) algorithm.SearchRequest {
+		return algorithm.SearchRequest{Target: nodes("Metal")[0], MaxPaths: 2, Constraints: algorithm.Constraints{Exclude: exclude}}
+	}
+	if cacheKey("bfs", request(nodes("Lava", "Mud"))) != cacheKey("BFS", request(nodes("Mud", "Lava", "Mud"))) {
+		t.Error("the same search got different keys")
+	}
+	if cacheKey("bfs", request(nodes("Lava"))) == cacheKey("bfs", request(nodes("Mud"))) {
+		t.Error("different searches got the same key")
+	}
+}
+
+// A restarted server answering differently must not confirm an ETag of the old answer
+func TestETagsDoNotOutliveTheirAnswer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const url = "/api/recipe?element=Stone"
+	before := serve(t, New(testGraph(t), Config{}), url).Header().Get("ETag")
+	if before == "" {
+		t.Fatal("no ETag")
+	}
+
+	recipes := testRecipes()
+	recipes.Recipe["Stone"] = [][]string{{"Mud", "Fire"}}
+	request := httptest.NewRequest(http.MethodGet, url, nil)
+	request.Header.Set("If-None-Match", before)
+	recorder := httptest.NewRecorder()
+	New(graphOf(t, recipes), Config{}).ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK || recorder.Header().Get("ETag") == before {
+		t.Errorf("the changed answer got %d with ETag %s, the old one was %s", recorder.Code, recorder.Header().Get("ETag"), before)
+	}
+
+	// Equal results get equal tags in any cache, different ones do not
+	graph := testGraph(t)
+	stone, _ := search.GetElementByName(graph, "Stone")
+	result, err := algorithm.Run("bfs", algorithm.SearchRequest{Target: stone, Graph: graph, MaxPaths: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := newResultCache(0, 0).put("stone", result)
+	second := newResultCache(0, 0).put("stone", result)
+	result.Trees = result.Trees[:1]
+	third := newResultCache(0, 0).put("stone", result)
+	if first.tag != second.tag || first.tag == third.tag {
+		t.Errorf("tags %s, %s and %s, want the first two equal and the last different", first.tag, second.tag, third.tag)
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"backend/search"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Config tunes New, its zero value serves with the default cache and without reloads
+type Config struct {
+	CacheEntries int   // Search results kept, 0 for the default and negative to turn the cache off
+	CacheBytes   int64 // Approximate memory the cached results may take, 0 for the default
+
+	// Precompute the single tree answers of astar, dfs and bfs for every element at startup,
+	// and reuse or write them at IndexFile when it is set
+	Index     bool
+	IndexFile string
+
+	// Loads the dataset again for POST /api/dataset/reload, which is only routed when set
+	Reload func() (*search.RecipeGraph, error)
+}
+
+// The graph every handler reads. A reload prepares what the handlers derive from the new graph
+// while requests are still served, then swaps both in while no request is running
+type dataset struct {
+	mu        sync.RWMutex
+	reloading sync.Mutex // Keeps two reloads from preparing at once
+	graph     *search.RecipeGraph
+	load      func() (*search.RecipeGraph, error)
+	reloaded  []func(graph *search.RecipeGraph) func()
+}
+
+// Middleware keeping a reload from swapping the graph under a running request
+func (data *dataset) hold(c *gin.Context) {
+	data.mu.RLock()
+	defer data.mu.RUnlock()
+	c.Next()
+}
+
+// The current graph as it is now. A reload swaps in new elements instead of changing the old
+// ones, so the snapshot stays usable without holding the graph
+func (data *dataset) snapshot() *search.RecipeGraph {
+	data.mu.RLock()
+	defer data.mu.RUnlock()
+	graph := *data.graph
+	return &graph
+}
+
+// Registers a function called with every newly loaded graph before it replaces the old one.
+// It should do its slow work right away and return a quick function installing the result,
+// which runs together with the swap
+func (data *dataset) onReload(prepare func(graph *search.RecipeGraph) func()) {
+	data.reloaded = append(data.reloaded, prepare)
+}
+
+// POST http://localhost:8080/api/dataset/reload
+// Must not run under hold, it waits for every request holding the graph to finish
+func (data *dataset) reload(c *gin.Context) {
+	data.reloading.Lock()
+	defer data.reloading.Unlock()
+
+	graph, err := data.load()
+	if err != nil {
+		log.Println("reloading dataset failed:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   true,
+			"type":    "reload_failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	install := make([]func(), len(data.reloaded))
+	for i, prepare := range data.reloaded {
+		install[i] = prepare(graph)
+	}
+
+	data.mu.Lock()
+	*data.graph = *graph
+	for _, swap := range install {
+		swap()
+	}
+	data.mu.Unlock()
+
+	c.JSON(http.StatusOK, gin.H{
+		"error": false,
+		"data":  search.Summarize(graph),
+	})
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"backend/search"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestReloadSwapsDerivedState(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recipes := testRecipes()
+	recipes.Element = append(recipes.Element, "Obsidian")
+	recipes.Recipe["Obsidian"] = [][]string{{"Lava", "Water"}}
+	recipes.Tiering["Obsidian"] = 2
+	router := New(testGraph(t), Config{
+		Index:  true,
+		Reload: func() (*search.RecipeGraph, error) { return graphOf(t, recipes), nil },
+	})
+
+	if code := serve(t, router, "/api/recipe?element=Obsidian").Code; code != http.StatusNotFound {
+		t.Fatalf("Obsidian answered %d before the reload, want 404", code)
+	}
+	if recorder := postJSON(t, router, "/api/dataset/reload", ""); recorder.Code != http.StatusOK {
+		t.Fatalf("reload answered %d: %s", recorder.Code, recorder.Body)
+	}
+
+	recorder := serve(t, router, "/api/recipe?element=Obsidian&algo=astar")
+	if recorder.Code != http.StatusOK || recorder.Header().Get("X-Cache") != fromIndex {
+		t.Errorf("Obsidian answered %d from %q, want 200 from the index", recorder.Code, recorder.Header().Get("X-Cache"))
+	}
+	if code := serve(t, router, "/api/elements/Obsidian/stats").Code; code != http.StatusOK {
+		t.Errorf("Obsidian has no analytics after the reload, got %d", code)
+	}
+	var body struct {
+		Data search.GraphStats `json:"data"`
+	}
+	if err := json.Unmarshal(serve(t, router, "/api/graph/stats").Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Data.Elements != len(recipes.Element) {
+		t.Errorf("graph stats count %d elements, want %d", body.Data.Elements, len(recipes.Element))
+	}
+}
+
+// Requests keep being answered while a reload prepares, only the swap waits for them
+func TestReloadPreparesWithoutBlockingRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	graph := testGraph(t)
+	data := &dataset{graph: graph, load: func() (*search.RecipeGraph, error) { return testGraph(t), nil }}
+	preparing, release := make(chan struct{}), make(chan struct{})
+	data.onReload(func(*search.RecipeGraph) func() {
+		close(preparing)
+		<-release
+		return func() {}
+	})
+	router := gin.New()
+	router.POST("/api/dataset/reload", data.reload)
+	router.Use(data.hold)
+	router.GET("/api/ping", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	reloaded := make(chan int, 1)
+	go func() { reloaded <- postJSON(t, router, "/api/dataset/reload", "").Code }()
+	<-preparing
+
+	answered := make(chan int, 1)
+	go func() { answered <- serve(t, router, "/api/ping").Code }()
+	select {
+	case code := <-answered:
+		if code != http.StatusNoContent {
+			t.Errorf("ping answered %d", code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("a request waited for the reload to prepare")
+	}
+
+	close(release)
+	if code := <-reloaded; code != http.StatusOK {
+		t.Errorf("reload answered %d", code)
+	}
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"backend/search"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// http://localhost:8080/api/elements/Acid%20Rain/stats
+func elementStats(analytics *search.Analytics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+		stats, err := analytics.Of(name)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   true,
+				"type":    "element_not_found",
+				"message": fmt.Sprintf("Element '%s' not found", name),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"error": false,
+			"data":  stats,
+		})
+	}
+}
+
+// http://localhost:8080/api/elements/ranking?by=difficulty&order=desc&limit=20
+func rankElements(analytics *search.Analytics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		metric := c.DefaultQuery("by", "difficulty")
+		order := strings.ToLower(c.DefaultQuery("order", "desc"))
+		if order != "asc" && order != "desc" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   true,
+				"type":    "invalid_parameter",
+				"message": "Order must be asc or desc",
+			})
+			return
+		}
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   true,
+				"type":    "invalid_parameter",
+				"message": "Limit parameter must be greater than 0",
+			})
+			return
+		}
+
+		ranking, err := analytics.Rank(metric, order == "asc", limit)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   true,
+				"type":    "invalid_parameter",
+				"message": fmt.Sprintf("By must be one of: %s", strings.Join(search.RankingMetrics, ", ")),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"error": false,
+			"data": gin.H{
+				"by":       metric,
+				"order":    order,
+				"elements": ranking,
+			},
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"backend/search"
+	"bytes"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var exportContentTypes = map[string]string{
+	"dot":     "text/vnd.graphviz; charset=utf-8",
+	"graphml": "application/graphml+xml; charset=utf-8",
+	"gexf":    "application/gexf+xml; charset=utf-8",
+}
+
+// http://localhost:8080/api/graph/export?format=dot|graphml|gexf
+// The whole recipe graph as a file for Graphviz or Gephi
+func exportGraph(graph *search.RecipeGraph) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := strings.ToLower(c.DefaultQuery("format", "dot"))
+		if !slices.Contains(search.ExportFormats, format) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   true,
+				"type":    "invalid_parameter",
+				"message": fmt.Sprintf("Format must be one of: %s", strings.Join(search.ExportFormats, ", ")),
+			})
+			return
+		}
+
+		var out bytes.Buffer
+		if err := search.Export(graph, format, &out); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   true,
+				"type":    "export_failed",
+				"message": err.Error(),
+			})
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=recipes.%s", format))
+		c.Data(http.StatusOK, exportContentTypes[format], out.Bytes())
+	}
+}
@@ -0,0 +1,24 @@
+package server
+
+import (
+	"backend/search"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// http://localhost:8080/api/graph/stats
+// Summary of the loaded dataset. The graph only changes on a reload, so it is computed once per dataset
+func graphStats(data *dataset) gin.HandlerFunc {
+	stats := search.Summarize(data.graph)
+	data.onReload(func(graph *search.RecipeGraph) func() {
+		next := search.Summarize(graph)
+		return func() { stats = next }
+	})
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"error": false,
+			"data":  stats,
+		})
+	}
+}
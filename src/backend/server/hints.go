@@ -0,0 +1,76 @@
+package server
+
+import (
+	"backend/hints"
+	"backend/search"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type hintRequest struct {
+	Discovered []string `json:"discovered"`
+	Level      string   `json:"level"` // ingredient, ingredients or result, ingredient by default
+	Limit      int      `json:"limit"` // Number of hints, 1 by default
+}
+
+// POST http://localhost:8080/api/hints {"discovered": ["Steam", "Lava"], "level": "ingredients"}
+// The next combinations worth trying for a player who has discovered the given elements
+func suggestHints(graph *search.RecipeGraph) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body hintRequest
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   true,
+				"type":    "invalid_parameter",
+				"message": "Body must list the discovered elements: " + err.Error(),
+			})
+			return
+		}
+		if body.Level == "" {
+			body.Level = hints.OneIngredient.String()
+		}
+		level, err := hints.ParseLevel(body.Level)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   true,
+				"type":    "invalid_parameter",
+				"message": err.Error(),
+			})
+			return
+		}
+		if body.Limit <= 0 {
+			body.Limit = 1
+		}
+
+		discovered := make([]*search.ElementNode, 0, len(body.Discovered))
+		for _, name := range body.Discovered {
+			element, err := search.GetElementByName(graph, name)
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error":   true,
+					"type":    "element_not_found",
+					"message": fmt.Sprintf("Element '%s' not found", name),
+				})
+				return
+			}
+			discovered = append(discovered, element)
+		}
+
+		suggestions := hints.Suggest(graph, discovered, body.Limit)
+		result := make([]hints.Hint, len(suggestions))
+		for i, suggestion := range suggestions {
+			result[i] = suggestion.Hint(level)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"error": false,
+			"data": gin.H{
+				"hints": result,
+				// Nothing left to suggest means everything reachable is discovered
+				"complete": len(result) == 0,
+			},
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"backend/algorithm"
+	"backend/search"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Algorithms whose single tree answers are precomputed: the first astar tree is a smallest one,
+// dfs gives the tree the current frontend shows first and bfs is the default of /api/recipe
+var indexedAlgorithms = []string{"astar", "dfs", "bfs"}
+
+// How long the searches for the dfs and bfs entries may take together, at startup and on every
+// reload. Elements left when it runs out are not indexed and get a live search instead
+var indexBudget = 20 * time.Second
+
+// The first tree of every indexed algorithm for every element, as an unconstrained search
+// for one tree would answer. The astar entries are a smallest tree of the element, with as many
+// combinations as the first astar tree but not necessarily the same one. Saved as JSON, so it
+// can be reused by the next start.
+// Search stats are not kept: no search runs for an index answer, so its stats are all zero
+type recipeIndex struct {
+	Dataset    string                           `json:"dataset"` // Fingerprint of the graph the trees belong to
+	Algorithms map[string]map[string]indexEntry `json:"algorithms"`
+}
+
+type indexEntry struct {
+	Tree *algorithm.RecipeTree `json:"tree"` // Nil for an element without a recipe tree
+}
+
+// Builds the astar entries from the smallest trees, and the others with one search per element
+// within indexBudget. Elements the search fails for or has no time left for are left out, so
+// asking for them falls back to the live search and its error
+func buildIndex(graph *search.RecipeGraph) *recipeIndex {
+	start := time.Now()
+	index := &recipeIndex{
+		Dataset:    fingerprint(graph),
+		Algorithms: make(map[string]map[string]indexEntry),
+	}
+	smallest := algorithm.SmallestTrees(graph)
+	skipped := 0
+	for _, algo := range indexedAlgorithms {
+		entries := make(map[string]indexEntry)
+		for _, node := range graph.Elements[1:] {
+			if algo == "astar" {
+				entries[node.Name] = indexEntry{Tree: smallest[node]}
+				continue
+			}
+			if time.Since(start) > indexBudget {
+				skipped++
+				continue
+			}
+			result, err := algorithm.Run(algo, algorithm.SearchRequest{Target: node, Graph: graph, MaxPaths: 1})
+			if err != nil {
+				continue
+			}
+			var entry indexEntry
+			if len(result.Trees) > 0 {
+				entry.Tree = result.Trees[0]
+			}
+			entries[node.Name] = entry
+		}
+		index.Algorithms[algo] = entries
+	}
+	log.Printf("Indexed %d elements in %s\n", len(graph.Elements)-1, time.Since(start).Round(time.Millisecond))
+	if skipped > 0 {
+		log.Printf("Ran out of time for %d searches, they are answered live\n", skipped)
+	}
+	return index
+}
+
+// Reads the index saved at path when it was built from this graph, otherwise builds it and
+// saves it there. An empty path only builds it
+func loadIndex(graph *search.RecipeGraph, path string) *recipeIndex {
+	if path == "" {
+		return buildIndex(graph)
+	}
+	index, err := readIndex(path)
+	switch {
+	case err == nil && index.Dataset == fingerprint(graph):
+		log.Println("Using the recipe index in", path)
+		return index
+	case err == nil:
+		log.Println("The recipe index in", path, "belongs to another dataset, rebuilding it")
+	case !errors.Is(err, fs.ErrNotExist):
+		log.Println("Reading the recipe index failed, rebuilding it:", err)
+	}
+
+	index = buildIndex(graph)
+	if err := index.save(path); err != nil {
+		log.Println("Saving the recipe index failed:", err)
+	}
+	return index
+}
+
+func readIndex(path string) (*recipeIndex, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var index recipeIndex
+	if err := json.NewDecoder(file).Decode(&index); err != nil {
+		return nil, err
+	}
+	return &index, nil
+}
+
+// Written next to path first, so a crash never leaves half an index behind
+func (index *recipeIndex) save(path string) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	if err := temp.Chmod(0o644); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := json.NewEncoder(temp).Encode(index); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
+
+// The answer of an unconstrained search for one tree, when the index holds it
+func (index *recipeIndex) lookup(algo string, req algorithm.SearchRequest) (algorithm.SearchResult, bool) {
+	if index == nil || req.MaxPaths != 1 || !req.Constraints.IsEmpty() {
+		return algorithm.SearchResult{}, false
+	}
+	algo = strings.ToLower(algo)
+	entry, ok := index.Algorithms[algo][req.Target.Name]
+	if !ok {
+		return algorithm.SearchResult{}, false
+	}
+	result := algorithm.SearchResult{
+		Algo:    algo,
+		Element: req.Target.Name,
+		Trees:   make([]*algorithm.RecipeTree, 0, 1),
+	}
+	if entry.Tree != nil {
+		result.Trees = append(result.Trees, entry.Tree)
+	}
+	return result, true
+}
+
+// Changes whenever an element, its tier or one of its recipes does
+func fingerprint(graph *search.RecipeGraph) string {
+	hash := fnv.New64a()
+	for _, node := range graph.Elements[1:] {
+		fmt.Fprintf(hash, "%s\x00%d\x00", node.Name, node.Tier)
+		for _, recipe := range node.Recipes {
+			for _, ingredient := range recipe {
+				fmt.Fprintf(hash, "%s\x00", ingredient.Name)
+			}
+			hash.Write([]byte{1})
+		}
+		hash.Write([]byte{2})
+	}
+	return fmt.Sprintf("%x", hash.Sum64())
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"backend/algorithm"
+	"backend/scraping"
+	"backend/search"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIndexAnswersLikeLiveSearch(t *testing.T) {
+	graph := testGraph(t)
+	path := filepath.Join(t.TempDir(), "index.json")
+	built := loadIndex(graph, path)
+	saved, err := readIndex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, index := range []*recipeIndex{built, saved} {
+		for _, algo := range indexedAlgorithms {
+			for _, node := range graph.Elements[1:] {
+				req := algorithm.SearchRequest{Target: node, Graph: graph, MaxPaths: 1}
+				live, err := algorithm.Run(algo, req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				indexed, ok := index.lookup(algo, req)
+				if !ok {
+					t.Fatalf("%s %s is not indexed", algo, node.Name)
+				}
+				want, _ := json.Marshal(live.Trees)
+				got, _ := json.Marshal(indexed.Trees)
+				if algo == "astar" && len(live.Trees) == 1 && len(indexed.Trees) == 1 {
+					// Any smallest tree will do, ties may be broken another way
+					if live.Trees[0].Size() != indexed.Trees[0].Size() {
+						t.Errorf("astar %s: index answers %s, live search %s", node.Name, got, want)
+					}
+				} else if string(got) != string(want) {
+					t.Errorf("%s %s: index answers %s, live search %s", algo, node.Name, got, want)
+				}
+				if indexed.Algo != live.Algo || indexed.Element != live.Element {
+					t.Errorf("%s %s: index answers for %s %s", algo, node.Name, indexed.Algo, indexed.Element)
+				}
+				if indexed.Stats != (algorithm.SearchStats{}) {
+					t.Errorf("%s %s: the index answers with stats %+v, want them zero", algo, node.Name, indexed.Stats)
+				}
+			}
+		}
+	}
+
+	// Other options are left to the live search
+	req := algorithm.SearchRequest{Target: graph.Elements[len(graph.Elements)-1], Graph: graph, MaxPaths: 2}
+	if _, ok := built.lookup("bfs", req); ok {
+		t.Error("a search for two trees was answered from the index")
+	}
+	req.MaxPaths, req.Constraints.Exclude = 1, graph.Elements[5:6]
+	if _, ok := built.lookup("bfs", req); ok {
+		t.Error("a constrained search was answered from the index")
+	}
+	if _, ok := built.lookup("iddfs", algorithm.SearchRequest{Target: req.Target, MaxPaths: 1}); ok {
+		t.Error("iddfs was answered from the index")
+	}
+}
+
+// The shortest entry comes from astar, not from the first bfs tree
+func TestIndexHoldsSmallestTrees(t *testing.T) {
+	graph := testGraph(t)
+	index := buildIndex(graph)
+	minimal := search.MinimalRecipes(graph)
+
+	for _, node := range graph.Elements[1:] {
+		result, ok := index.lookup("astar", algorithm.SearchRequest{Target: node, Graph: graph, MaxPaths: 1})
+		if !ok || len(result.Trees) != 1 {
+			t.Fatalf("astar %s is not indexed", node.Name)
+		}
+		if got, want := result.Trees[0].Size(), minimal[node].Size; got != want {
+			t.Errorf("%s: indexed tree has %d combinations, smallest has %d", node.Name, got, want)
+		}
+	}
+}
+
+func TestIndexFileOfAnotherDatasetIsRebuilt(t *testing.T) {
+	graph := testGraph(t)
+	path := filepath.Join(t.TempDir(), "index.json")
+	stale := &recipeIndex{Dataset: "other", Algorithms: map[string]map[string]indexEntry{}}
+	if err := stale.save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if index := loadIndex(graph, path); index.Dataset != fingerprint(graph) || len(index.Algorithms["bfs"]) == 0 {
+		t.Fatal("the stale index was used")
+	}
+	if saved, err := readIndex(path); err != nil || saved.Dataset != fingerprint(graph) {
+		t.Fatalf("the rebuilt index was not saved: %v", err)
+	}
+}
+
+// Elements per tier of the generated dataset, as in the algorithm tests
+var syntheticTiers = []int{4, 30, 50, 60, 70, 70, 70, 65, 60, 55, 50, 40, 30, 25, 20, 15}
+
+// The generated dataset of the algorithm tests, the size and shape of the scraped one. Every
+// element has one to eight recipes, each combining the tier just below with any lower tier,
+// plus an occasional recipe of the element's own tier that the tier rule prunes
+func syntheticGraph(t *testing.T, seed uint64) *search.RecipeGraph {
+	t.Helper()
+	random := rand.New(rand.NewPCG(seed, 0))
+	recipes := scraping.RecipeEntry{Recipe: make(map[string][][]string), Tiering: make(map[string]int)}
+	byTier := make([][]string, len(syntheticTiers))
+	for tier, count := range syntheticTiers {
+		for i := range count {
+			name := fmt.Sprintf("T%d-%d", tier, i)
+			if tier == 0 {
+				name = []string{"Air", "Earth", "Fire", "Water"}[i]
+				recipes.Recipe[name] = [][]string{{"", ""}}
+			}
+			recipes.Element = append(recipes.Element, name)
+			recipes.Tiering[name] = tier
+			byTier[tier] = append(byTier[tier], name)
+		}
+	}
+
+	pick := func(tier int) string { return byTier[tier][random.IntN(len(byTier[tier]))] }
+	for tier := 1; tier < len(byTier); tier++ {
+		for _, name := range byTier[tier] {
+			for range 1 + random.IntN(8) {
+				pair := []string{pick(tier - 1), pick(random.IntN(tier))}
+				if random.IntN(10) == 0 {
+					pair[1] = pick(tier)
+				}
+				random.Shuffle(2, func(i, j int) { pair[i], pair[j] = pair[j], pair[i] })
+				recipes.Recipe[name] = append(recipes.Recipe[name], pair)
+			}
+		}
+	}
+	return graphOf(t, recipes)
+}
+
+// A dataset the size of the scraped one is indexed within the budget, with a smallest astar
+// tree for every element. Searching astar for every entry used to take gigabytes instead
+func TestIndexBuildsWithinBudget(t *testing.T) {
+	budget := indexBudget
+	indexBudget = 2 * time.Second
+	t.Cleanup(func() { indexBudget = budget })
+	graph := syntheticGraph(t, 0)
+
+	built := make(chan *recipeIndex, 1)
+	go func() { built <- buildIndex(graph) }()
+	var index *recipeIndex
+	select {
+	case index = <-built:
+	case <-time.After(indexBudget + 10*time.Second):
+		t.Fatal("the index was not built within the budget")
+	}
+
+	minimal := search.MinimalRecipes(graph)
+	for _, node := range graph.Elements[1:] {
+		result, ok := index.lookup("astar", algorithm.SearchRequest{Target: node, Graph: graph, MaxPaths: 1})
+		if !ok {
+			t.Fatalf("astar %s is not indexed", node.Name)
+		}
+		smallest, craftable := minimal[node]
+		if craftable != (len(result.Trees) == 1) || craftable && result.Trees[0].Size() != smallest.Size {
+			t.Errorf("%s: indexed %d trees, want one with %d combinations", node.Name, len(result.Trees), smallest.Size)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package server
+
+import (
+	"backend/algorithm"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// A route together with everything the OpenAPI document says about it.
+// Routes listed here are registered from this list, so the document cannot miss one of them
+type endpoint struct {
+	Method      string
+	Path        string
+	OperationID string
+	Summary     string
+	Query       any // Struct the handler binds the query string into
+	Responses   map[int]response
+	Handler     func(s *searcher) gin.HandlerFunc
+}
+
+type response struct {
+	Description string
+	Body        any               // Zero value of the JSON body, nil for an answer without one
+	Rendered    bool              // Can also answer with one of the render formats
+	Headers     map[string]string // Response headers by name, with their description
+}
+
+// Headers of a search answer that may come from the index or the cache, see writeCacheHeaders
+var cacheHeaders = map[string]string{
+	"ETag":          "Identifies this exact answer, send it back in If-None-Match. Absent when the result was not stored",
+	"Cache-Control": "How long the answer may be reused, no-store when it has no ETag",
+	"X-Cache":       "Where the answer came from: MISS for a live search, HIT for the result cache, INDEX for the precomputed index",
+}
+
+// Sent with an ETag from an earlier answer to get 304 Not Modified when it still holds
+var ifNoneMatch = gin.H{
+	"name":        "If-None-Match",
+	"in":          "header",
+	"required":    false,
+	"description": "ETags of answers the client already holds",
+	"schema":      gin.H{"type": "string"},
+}
+
+var searchErrors = map[int]response{
+	http.StatusBadRequest:          {Description: "Missing or invalid parameter, or unknown algorithm", Body: errorResponse{}},
+	http.StatusNotFound:            {Description: "Unknown element, or no recipe under the given constraints", Body: errorResponse{}},
+	http.StatusUnprocessableEntity: {Description: "The search gave up before finding a tree, it outgrew its state limit or too many trees missed an included element", Body: errorResponse{}},
+	http.StatusInternalServerError: {Description: "The search failed", Body: errorResponse{}},
+}
+
+// Adds the error answers, the cache headers and the 304 of a revalidated answer to a search's 200
+func withSearchErrors(ok response) map[int]response {
+	ok.Headers = cacheHeaders
+	responses := map[int]response{
+		http.StatusOK:          ok,
+		http.StatusNotModified: {Description: "The answer matching If-None-Match is still current", Headers: cacheHeaders},
+	}
+	for status, failure := range searchErrors {
+		responses[status] = failure
+	}
+	return responses
+}
+
+var documentedEndpoints = []endpoint{
+	{
+		Method:      http.MethodGet,
+		Path:        "/api/recipe",
+		OperationID: "getRecipe",
+		Summary:     "One recipe tree for an element",
+		Query:       recipeQuery{},
+		Responses:   withSearchErrors(response{Description: "The recipe tree, or its drawing when format is not json", Body: recipeResponse{}, Rendered: true}),
+		Handler:     getRecipe,
+	},
+	{
+		Method:      http.MethodGet,
+		Path:        "/api/recipes",
+		OperationID: "getRecipes",
+		Summary:     "Up to max distinct recipe trees for an element",
+		Query:       recipeQuery{},
+		Responses:   withSearchErrors(response{Description: "The recipe trees, or their drawing when format is not json", Body: recipeResponse{}, Rendered: true}),
+		Handler:     getRecipes,
+	},
+}
+
+// Values for the enum struct tag, looked up when the document is built
+var enumValues = map[string]func() []string{
+	"algorithms": algorithm.Algorithms,
+	"formats":    func() []string { return append([]string{"json"}, algorithm.RenderFormats...) },
+	"errorTypes": func() []string { return errorTypes },
+}
+
+// OpenAPI 3 document of documentedEndpoints, built from the query and response types by reflection
+func openAPIDocument(endpoints []endpoint) gin.H {
+	builder := &schemaBuilder{components: make(map[string]any)}
+	paths := make(map[string]gin.H)
+	for _, e := range endpoints {
+		parameters := builder.queryParameters(e.Query)
+		if _, revalidates := e.Responses[http.StatusNotModified]; revalidates {
+			parameters = append(parameters, ifNoneMatch)
+		}
+		operation := gin.H{
+			"operationId": e.OperationID,
+			"summary":     e.Summary,
+			"parameters":  parameters,
+			"responses":   builder.responses(e.Responses),
+		}
+		if paths[e.Path] == nil {
+			paths[e.Path] = make(gin.H)
+		}
+		paths[e.Path][strings.ToLower(e.Method)] = operation
+	}
+
+	return gin.H{
+		"openapi": "3.0.3",
+		"info": gin.H{
+			"title":       "Little Alchemy 2 recipe API",
+			"description": "Recipe trees for Little Alchemy 2 elements, searched over the scraped recipe graph",
+			"version":     "1",
+		},
+		"paths":      paths,
+		"components": gin.H{"schemas": builder.components},
+	}
+}
+
+type schemaBuilder struct {
+	components map[string]any // Named struct schemas, referenced with $ref
+}
+
+func (builder *schemaBuilder) queryParameters(query any) []gin.H {
+	parameters := make([]gin.H, 0)
+	if query == nil {
+		return parameters
+	}
+	t := reflect.TypeOf(query)
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("form")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		schema := builder.fieldSchema(field)
+		delete(schema, "description") // Already on the parameter
+		if value, ok := strings.CutPrefix(options, "default="); ok {
+			schema["default"] = defaultValue(field.Type, value)
+		}
+		parameter := gin.H{
+			"name":     name,
+			"in":       "query",
+			"required": field.Tag.Get("required") == "true",
+			"schema":   schema,
+		}
+		if doc := field.Tag.Get("doc"); doc != "" {
+			parameter["description"] = doc
+		}
+		parameters = append(parameters, parameter)
+	}
+	return parameters
+}
+
+func defaultValue(t reflect.Type, value string) any {
+	switch t.Kind() {
+	case reflect.Int:
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+func (builder *schemaBuilder) responses(responses map[int]response) gin.H {
+	out := make(gin.H)
+	for status, r := range responses {
+		documented := gin.H{"description": r.Description}
+		if r.Body != nil {
+			content := gin.H{"application/json": gin.H{"schema": builder.schema(reflect.TypeOf(r.Body))}}
+			if r.Rendered {
+				for _, contentType := range renderContentTypes {
+					content[contentType] = gin.H{"schema": gin.H{"type": "string"}}
+				}
+			}
+			documented["content"] = content
+		}
+		if len(r.Headers) > 0 {
+			headers := make(gin.H)
+			for name, description := range r.Headers {
+				headers[name] = gin.H{"description": description, "schema": gin.H{"type": "string"}}
+			}
+			documented["headers"] = headers
+		}
+		out[strconv.Itoa(status)] = documented
+	}
+	return out
+}
+
+// Schema of a struct field with its doc and enum tags applied
+func (builder *schemaBuilder) fieldSchema(field reflect.StructField) gin.H {
+	schema := builder.schema(field.Type)
+	doc := field.Tag.Get("doc")
+	enum := field.Tag.Get("enum")
+	if _, isRef := schema["$ref"]; isRef && (doc != "" || enum != "") {
+		// Siblings of $ref are ignored in OpenAPI 3.0
+		schema = gin.H{"allOf": []gin.H{schema}}
+	}
+	if doc != "" {
+		schema["description"] = doc
+	}
+	if values, ok := enumValues[enum]; ok {
+		target := schema
+		if items, isArray := schema["items"].(gin.H); isArray {
+			target = items
+		}
+		target["enum"] = values()
+	}
+	return schema
+}
+
+func (builder *schemaBuilder) schema(t reflect.Type) gin.H {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return gin.H{"type": "string"}
+	case reflect.Bool:
+		return gin.H{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return gin.H{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return gin.H{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return gin.H{"type": "array", "items": builder.schema(t.Elem())}
+	case reflect.Map:
+		return gin.H{"type": "object", "additionalProperties": builder.schema(t.Elem())}
+	case reflect.Struct:
+		return builder.component(t)
+	}
+	// Interfaces can hold anything
+	return gin.H{}
+}
+
+func (builder *schemaBuilder) component(t reflect.Type) gin.H {
+	name := []rune(t.Name())
+	name[0] = unicode.ToUpper(name[0])
+	ref := gin.H{"$ref": "#/components/schemas/" + string(name)}
+	if _, done := builder.components[string(name)]; done {
+		return ref
+	}
+	// Claimed before the fields so recursive types such as RecipeTree end in a $ref
+	builder.components[string(name)] = nil
+
+	properties := make(gin.H)
+	required := make([]string, 0)
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		jsonName, options, _ := strings.Cut(tag, ",")
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+		properties[jsonName] = builder.fieldSchema(field)
+		if !slices.Contains(strings.Split(options, ","), "omitempty") {
+			required = append(required, jsonName)
+		}
+	}
+	schema := gin.H{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	builder.components[string(name)] = schema
+	return ref
+}
+
+// GET http://localhost:8080/api/openapi.json
+func serveOpenAPI(document gin.H) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, document)
+	}
+}
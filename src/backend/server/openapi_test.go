@@ -0,0 +1,316 @@
+package server
+
+import (
+	"backend/algorithm"
+	"backend/scraping"
+	"backend/search"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// A fresh copy every call, so a test may add elements of its own
+func testRecipes() scraping.RecipeEntry {
+	return scraping.RecipeEntry{
+		Element: []string{"Air", "Earth", "Fire", "Water", "Lava", "Mud", "Stone", "Metal"},
+		Recipe: map[string][][]string{
+			"Air":   {{"", ""}},
+			"Earth": {{"", ""}},
+			"Fire":  {{"", ""}},
+			"Water": {{"", ""}},
+			"Lava":  {{"Earth", "Fire"}},
+			"Mud":   {{"Earth", "Water"}},
+			"Stone": {{"Lava", "Air"}, {"Mud", "Fire"}},
+			"Metal": {{"Stone", "Fire"}},
+		},
+		Tiering: map[string]int{
+			"Air": 0, "Earth": 0, "Fire": 0, "Water": 0, "Lava": 1, "Mud": 1, "Stone": 2, "Metal": 3,
+		},
+	}
+}
+
+func testGraph(t *testing.T) *search.RecipeGraph {
+	t.Helper()
+	return graphOf(t, testRecipes())
+}
+
+func graphOf(t *testing.T, recipes scraping.RecipeEntry) *search.RecipeGraph {
+	t.Helper()
+	var graph search.RecipeGraph
+	if err := search.ConstructRecipeGraph(recipes, &graph); err != nil {
+		t.Fatal(err)
+	}
+	return &graph
+}
+
+// Requests covering every branch of the documented handlers, success and failure alike.
+// Each answer must use a documented status and match the schema documented for it
+var driftRequests = []string{
+	"/api/recipe?element=Metal",
+	"/api/recipe?element=Metal&algo=dfs&legacy=true",
+	"/api/recipe?element=Metal&algo=bfs&legacy=true",
+	"/api/recipe?element=Metal&algo=astar&include=Mud",
+	"/api/recipe?element=Metal&format=svg",
+	"/api/recipe",
+	"/api/recipe?element=Gold",
+	"/api/recipe?element=Metal&algo=quantum",
+	"/api/recipe?element=Metal&format=pdf",
+	"/api/recipe?element=Metal&exclude=Lava,Mud",
+	"/api/recipe?element=Metal&include=Gold",
+	"/api/recipe?element=Metal&legacy=maybe",
+	"/api/recipes?element=Stone&max=3",
+	"/api/recipes?element=Stone&max=3&algo=dfs&legacy=true",
+	"/api/recipes?element=Gold",
+	"/api/recipes?element=Stone&max=0",
+	"/api/recipes?element=Stone&max=many",
+	"/api/recipes?element=Air&algo=iddfs",
+	"/api/recipes?element=Stone&algo=bidirectional",
+}
+
+func TestOpenAPIMatchesHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := New(testGraph(t), Config{})
+
+	var spec map[string]any
+	if err := json.Unmarshal(serve(t, router, "/api/openapi.json").Body.Bytes(), &spec); err != nil {
+		t.Fatal(err)
+	}
+	paths := spec["paths"].(map[string]any)
+	schemas := spec["components"].(map[string]any)["schemas"].(map[string]any)
+
+	// Every documented operation is routed
+	for path, operations := range paths {
+		for method := range operations.(map[string]any) {
+			if !slices.ContainsFunc(router.Routes(), func(route gin.RouteInfo) bool {
+				return route.Path == path && strings.EqualFold(route.Method, method)
+			}) {
+				t.Errorf("%s %s is documented but not routed", method, path)
+			}
+		}
+	}
+
+	covered := make(map[string]bool)
+	for _, url := range driftRequests {
+		path, query, _ := strings.Cut(url, "?")
+		operation, ok := paths[path].(map[string]any)["get"].(map[string]any)
+		if !ok {
+			t.Fatalf("%s is not documented", path)
+		}
+
+		for name := range queryNames(query) {
+			if !slices.ContainsFunc(operation["parameters"].([]any), func(p any) bool {
+				return p.(map[string]any)["name"] == name
+			}) {
+				t.Errorf("%s: query parameter %s is not documented", url, name)
+			}
+		}
+
+		recorder := serve(t, router, url)
+		checkDocumented(t, url, recorder, operation, schemas, covered)
+		// Asking again with the ETag revalidates the answer
+		if etag := recorder.Header().Get("ETag"); etag != "" {
+			if !slices.ContainsFunc(operation["parameters"].([]any), func(p any) bool {
+				return p.(map[string]any)["name"] == "If-None-Match"
+			}) {
+				t.Errorf("%s: the If-None-Match header is not documented", url)
+			}
+			request := httptest.NewRequest(http.MethodGet, url, nil)
+			request.Header.Set("If-None-Match", etag)
+			revalidated := httptest.NewRecorder()
+			router.ServeHTTP(revalidated, request)
+			checkDocumented(t, url+" with If-None-Match", revalidated, operation, schemas, covered)
+		}
+	}
+
+	// Answers no request against the test graph can provoke: a failing search, and one that
+	// gives up after thousands of rejected trees or partial trees
+	untested := []int{http.StatusInternalServerError, http.StatusUnprocessableEntity}
+	for _, e := range documentedEndpoints {
+		for status := range e.Responses {
+			if !slices.Contains(untested, status) && !covered[fmt.Sprintf("%s %d", e.Path, status)] {
+				t.Errorf("no drift request answers %s with %d", e.Path, status)
+			}
+		}
+	}
+}
+
+// Checks an answer against the documented status, headers and body schema of operation
+func checkDocumented(t *testing.T, url string, recorder *httptest.ResponseRecorder, operation, schemas map[string]any, covered map[string]bool) {
+	t.Helper()
+	path, _, _ := strings.Cut(url, "?")
+	status := strconv.Itoa(recorder.Code)
+	documented, ok := operation["responses"].(map[string]any)[status].(map[string]any)
+	if !ok {
+		t.Errorf("%s: status %s is not documented", url, status)
+		return
+	}
+	covered[path+" "+status] = true
+
+	headers, _ := documented["headers"].(map[string]any)
+	for _, name := range []string{"ETag", "Cache-Control", "X-Cache"} {
+		if _, ok := headers[name]; recorder.Header().Get(name) != "" && !ok {
+			t.Errorf("%s: header %s is not documented for %s", url, name, status)
+		}
+	}
+
+	content, ok := documented["content"].(map[string]any)
+	if !ok {
+		if recorder.Body.Len() != 0 {
+			t.Errorf("%s: %s is documented without a body but has one", url, status)
+		}
+		return
+	}
+	contentType, _, _ := strings.Cut(recorder.Header().Get("Content-Type"), ";")
+	media, ok := content[contentType].(map[string]any)
+	if !ok {
+		t.Errorf("%s: content type %s is not documented for %s", url, contentType, status)
+		return
+	}
+	if contentType != "application/json" {
+		return
+	}
+	var body any
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("%s: %v", url, err)
+	}
+	if err := validate(body, media["schema"].(map[string]any), schemas, "body"); err != nil {
+		t.Errorf("%s: %v", url, err)
+	}
+}
+
+func serve(t *testing.T, router http.Handler, url string) *httptest.ResponseRecorder {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, url, nil))
+	return recorder
+}
+
+func queryNames(query string) map[string]bool {
+	names := make(map[string]bool)
+	for _, pair := range strings.Split(query, "&") {
+		if name, _, _ := strings.Cut(pair, "="); name != "" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// Checks a decoded JSON value against the subset of JSON Schema the generator emits.
+// Fields missing from the schema are drift as much as required fields missing from the value
+func validate(value any, schema map[string]any, schemas map[string]any, at string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		return validate(value, schemas[strings.TrimPrefix(ref, "#/components/schemas/")].(map[string]any), schemas, at)
+	}
+	if all, ok := schema["allOf"].([]any); ok {
+		for _, part := range all {
+			if err := validate(value, part.(map[string]any), schemas, at); err != nil {
+				return err
+			}
+		}
+	}
+	if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, value) {
+		return fmt.Errorf("%s: %v is not one of %v", at, value, enum)
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: expected an object, got %T", at, value)
+		}
+		for _, name := range schemaStrings(schema["required"]) {
+			if _, ok := object[name]; !ok {
+				return fmt.Errorf("%s: required field %s is missing", at, name)
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		for name, field := range object {
+			fieldSchema, ok := properties[name].(map[string]any)
+			if !ok {
+				fieldSchema, ok = schema["additionalProperties"].(map[string]any)
+			}
+			if !ok {
+				return fmt.Errorf("%s: field %s is not documented", at, name)
+			}
+			if err := validate(field, fieldSchema, schemas, at+"."+name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s: expected an array, got %T", at, value)
+		}
+		for i, item := range array {
+			if err := validate(item, schema["items"].(map[string]any), schemas, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s: expected a string, got %T", at, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected a boolean, got %T", at, value)
+		}
+	case "integer", "number":
+		number, ok := value.(float64)
+		if !ok || (schema["type"] == "integer" && number != math.Trunc(number)) {
+			return fmt.Errorf("%s: expected an %s, got %v", at, schema["type"], value)
+		}
+	}
+	return nil
+}
+
+func schemaStrings(value any) []string {
+	list, _ := value.([]any)
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		out = append(out, item.(string))
+	}
+	return out
+}
+
+// The CLI prints searches through RecipeResponse, so its JSON is what /api/recipes answers
+func TestRecipeResponseMatchesAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	graph := testGraph(t)
+	router := New(graph, Config{CacheEntries: -1})
+
+	target, err := search.GetElementByName(graph, "Stone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := algorithm.Run("bfs", algorithm.SearchRequest{Target: target, Graph: graph, MaxPaths: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	printed, err := json.Marshal(RecipeResponse(result))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got, want map[string]any
+	if err := json.Unmarshal(printed, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(serve(t, router, "/api/recipes?element=Stone&max=2").Body.Bytes(), &want); err != nil {
+		t.Fatal(err)
+	}
+	// Only the timing differs between two runs
+	for _, body := range []map[string]any{got, want} {
+		delete(body["data"].(map[string]any)["stats"].(map[string]any), "wallTimeMs")
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RecipeResponse = %v, /api/recipes = %v", got, want)
+	}
+}
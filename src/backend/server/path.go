@@ -0,0 +1,74 @@
+package server
+
+import (
+	"backend/algorithm"
+	"backend/search"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// http://localhost:8080/api/path?from=Fire&to=Acid%20Rain
+// Shortest crafting chain from one element to another, with the full recipe tree around it
+func findChain(graph *search.RecipeGraph) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		from := c.Query("from")
+		to := c.Query("to")
+
+		if from == "" || to == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   true,
+				"type":    "missing_parameter",
+				"message": "From and to parameters are required",
+			})
+			return
+		}
+
+		source, err := search.GetElementByName(graph, from)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   true,
+				"type":    "element_not_found",
+				"message": fmt.Sprintf("Element '%s' not found", from),
+			})
+			return
+		}
+		target, err := search.GetElementByName(graph, to)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   true,
+				"type":    "element_not_found",
+				"message": fmt.Sprintf("Element '%s' not found", to),
+			})
+			return
+		}
+
+		constraints, ok := parseConstraints(c, graph)
+		if !ok {
+			return
+		}
+
+		result, err := algorithm.Run("bidirectional", algorithm.SearchRequest{
+			Target:      target,
+			Source:      source,
+			Graph:       graph,
+			Constraints: constraints,
+		})
+		if err != nil {
+			writeSearchError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"error": false,
+			"data": gin.H{
+				"from":  from,
+				"to":    to,
+				"chain": result.Chain,
+				"tree":  result.Trees[0],
+				"stats": result.Stats,
+			},
+		})
+	}
+}
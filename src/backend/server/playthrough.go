@@ -0,0 +1,60 @@
+package server
+
+import (
+	"backend/algorithm"
+	"backend/search"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// http://localhost:8080/api/playthrough?after=50
+// Combinations that discover every reachable element, starting from the base elements.
+// POST a checkpoint as the body to continue from it instead. With after=N the response
+// also holds the checkpoint to save once the first N steps are done
+func planPlaythrough(graph *search.RecipeGraph) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var start algorithm.Checkpoint
+		if c.Request.Method == http.MethodPost {
+			if err := c.ShouldBindJSON(&start); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   true,
+					"type":    "invalid_parameter",
+					"message": "Body must be a playthrough checkpoint: " + err.Error(),
+				})
+				return
+			}
+		}
+
+		plan, err := algorithm.PlanPlaythrough(graph, start)
+		if err != nil {
+			writeSearchError(c, err)
+			return
+		}
+
+		data := gin.H{
+			"start":       plan.Start,
+			"steps":       plan.Steps,
+			"total":       len(plan.Steps),
+			"unreachable": plan.Unreachable,
+		}
+		if after := c.Query("after"); after != "" {
+			done, err := strconv.Atoi(after)
+			if err != nil || done < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   true,
+					"type":    "invalid_parameter",
+					"message": "After parameter must be a non-negative number of steps",
+				})
+				return
+			}
+			data["checkpoint"] = plan.CheckpointAt(done)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"error": false,
+			"data":  data,
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"backend/algorithm"
+	"backend/search"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Query of /api/recipe and /api/recipes. The tags drive both binding and the OpenAPI document
+type recipeQuery struct {
+	Element string   `form:"element" doc:"Element to craft, matched exactly" required:"true"`
+	Algo    string   `form:"algo,default=bfs" doc:"Search algorithm" enum:"algorithms"`
+	Max     int      `form:"max,default=5" doc:"Number of recipe trees, at most 1000. /api/recipe always looks for one"`
+	Format  string   `form:"format,default=json" doc:"Answer with the trees drawn in another format instead of JSON" enum:"formats"`
+	Legacy  bool     `form:"legacy,default=false" doc:"Answer in the per-algorithm shapes the current frontend reads"`
+	Include []string `form:"include" doc:"Elements every tree must contain, comma separated or repeated"`
+	Exclude []string `form:"exclude" doc:"Elements no tree may contain, comma separated or repeated"`
+}
+
+type recipeResponse struct {
+	Error bool       `json:"error"`
+	Data  recipeData `json:"data"`
+}
+
+type recipeData struct {
+	Element      string                   `json:"element"`
+	Algo         string                   `json:"algo"`
+	Trees        *[]*algorithm.RecipeTree `json:"trees,omitempty" doc:"Recipe trees in discovery order, left out when legacy is set"`
+	Stats        algorithm.SearchStats    `json:"stats"`
+	VisitedNodes int                      `json:"visitedNodes" doc:"Same as stats.nodesExpanded"`
+	Paths        *[]any                   `json:"paths,omitempty" doc:"Legacy only: BFS graphs or DFS path maps, one per tree"`
+	Nodes        *algorithm.PathResult    `json:"nodes,omitempty" doc:"Legacy DFS on /api/recipe only: the first path"`
+}
+
+// Flat error body of the v1 routes
+type errorResponse struct {
+	Error    bool     `json:"error"`
+	Type     string   `json:"type" doc:"Stable error code" enum:"errorTypes"`
+	Message  string   `json:"message"`
+	Blocking []string `json:"blocking,omitempty" doc:"Excluded elements every recipe needs, unreachable_under_constraints only"`
+}
+
+// Binds and checks the query, writes the error response and returns false when it is unusable
+func bindRecipeQuery(c *gin.Context, graph *search.RecipeGraph) (recipeQuery, *search.ElementNode, algorithm.Constraints, bool) {
+	var query recipeQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		writeSearchError(c, newAPIError(http.StatusBadRequest, errInvalidParameter, "Invalid query: %s", err.Error()))
+		return query, nil, algorithm.Constraints{}, false
+	}
+	query.Algo = strings.ToLower(query.Algo)
+
+	if query.Element == "" {
+		writeSearchError(c, newAPIError(http.StatusBadRequest, errMissingParameter, "Element parameter is required"))
+		return query, nil, algorithm.Constraints{}, false
+	}
+	node, err := search.GetElementByName(graph, query.Element)
+	if err != nil {
+		writeSearchError(c, elementNotFound(query.Element))
+		return query, nil, algorithm.Constraints{}, false
+	}
+	constraints, ok := parseConstraints(c, graph)
+	if !ok {
+		return query, nil, constraints, false
+	}
+	format, ok := parseFormat(c)
+	query.Format = format
+	return query, node, constraints, ok
+}
+
+// http://localhost:8080/api/recipe?element=Acid%20Rain&algo=bfs|dfs[&legacy=true][&format=json|mermaid|dot|svg|text|md]
+// legacy=true answers in the per-algorithm shapes the current frontend reads
+func getRecipe(s *searcher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query, node, constraints, ok := bindRecipeQuery(c, s.graph)
+		if !ok {
+			return
+		}
+
+		result, err := s.run(query.Algo, algorithm.SearchRequest{
+			Target:      node,
+			Graph:       s.graph,
+			MaxPaths:    1,
+			Constraints: constraints,
+		})
+		if err != nil {
+			writeSearchError(c, err)
+			return
+		}
+		log.Printf("Jumlah node yang dikunjungi: %d\n", result.Stats.NodesExpanded)
+		if writeCacheHeaders(c, result) {
+			return
+		}
+		if query.Format != "json" {
+			writeRendered(c, query.Format, result.Trees)
+			return
+		}
+
+		data := newRecipeData(query, result.SearchResult)
+		// The single recipe DFS view reads the first path from "nodes"
+		if query.Legacy && result.Algo == "dfs" {
+			nodes := algorithm.PathResult{}
+			if len(*data.Paths) > 0 {
+				nodes = (*data.Paths)[0].(algorithm.PathResult)
+			}
+			data.Nodes = &nodes
+		}
+		c.JSON(http.StatusOK, recipeResponse{Data: data})
+	}
+}
+
+// http://localhost:8080/api/recipes?element=Acid%20Rain&algo=bfs|dfs&max=5[&legacy=true][&format=..]
+func getRecipes(s *searcher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query, node, constraints, ok := bindRecipeQuery(c, s.graph)
+		if !ok {
+			return
+		}
+		if query.Max <= 0 || query.Max > maxTrees {
+			writeSearchError(c, invalidMax())
+			return
+		}
+
+		result, err := s.run(query.Algo, algorithm.SearchRequest{
+			Target:      node,
+			Graph:       s.graph,
+			MaxPaths:    query.Max,
+			Constraints: constraints,
+		})
+		if err != nil {
+			writeSearchError(c, err)
+			return
+		}
+		if writeCacheHeaders(c, result) {
+			return
+		}
+		if query.Format != "json" {
+			writeRendered(c, query.Format, result.Trees)
+			return
+		}
+		c.JSON(http.StatusOK, recipeResponse{Data: newRecipeData(query, result.SearchResult)})
+	}
+}
+
+// RecipeResponse is the body /api/recipes answers a search with, for printing results elsewhere
+func RecipeResponse(result algorithm.SearchResult) any {
+	return recipeResponse{Data: newRecipeData(recipeQuery{Element: result.Element}, result)}
+}
+
+func newRecipeData(query recipeQuery, result algorithm.SearchResult) recipeData {
+	data := recipeData{
+		Element:      query.Element,
+		Algo:         result.Algo,
+		Trees:        &result.Trees,
+		Stats:        result.Stats,
+		VisitedNodes: result.Stats.NodesExpanded,
+	}
+	if query.Legacy {
+		paths := result.LegacyPaths() // ← what your frontend expects
+		data.Trees, data.Paths = nil, &paths
+	}
+	return data
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"backend/algorithm"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var renderContentTypes = map[string]string{
+	"mermaid": "text/plain; charset=utf-8",
+	"dot":     "text/vnd.graphviz; charset=utf-8",
+	"svg":     "image/svg+xml",
+	"text":    "text/plain; charset=utf-8",
+	"md":      "text/markdown; charset=utf-8",
+}
+
+// The format= query, json unless one of algorithm.RenderFormats.
+// Writes the error response and returns false when the format is unknown
+func parseFormat(c *gin.Context) (string, bool) {
+	format := strings.ToLower(c.DefaultQuery("format", "json"))
+	if format != "json" && !slices.Contains(algorithm.RenderFormats, format) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   true,
+			"type":    "invalid_parameter",
+			"message": fmt.Sprintf("Format must be one of: json, %s", strings.Join(algorithm.RenderFormats, ", ")),
+		})
+		return "", false
+	}
+	return format, true
+}
+
+// Answers with the trees drawn in a non-JSON format
+func writeRendered(c *gin.Context, format string, trees []*algorithm.RecipeTree) {
+	rendered, err := algorithm.Render(format, trees)
+	if err != nil {
+		writeSearchError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, renderContentTypes[format], []byte(rendered))
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"backend/algorithm"
+	"backend/search"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// New sets up every API route over a loaded graph
+func New(graph *search.RecipeGraph, config Config) *gin.Engine {
+	r := gin.Default()
+	r.SetTrustedProxies([]string{"127.0.0.1"})
+	r.Use(cors.New(cors.Config{
+	    AllowOrigins:     []string{"*"}, // Mengizinkan semua origin saat pengembangan
+	    AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+	    AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
+	    ExposeHeaders:    []string{"Content-Length", "ETag", "X-Cache"},
+	    AllowCredentials: true,
+	}))
+
+	data := &dataset{graph: graph, load: config.Reload}
+	cache := newResultCache(config.CacheEntries, config.CacheBytes)
+	data.onReload(func(*search.RecipeGraph) func() { return cache.purge })
+	// Routed before hold is added, see dataset.reload
+	if config.Reload != nil {
+		r.POST("/api/dataset/reload", data.reload)
+	}
+	// Streams can stay open for minutes, so they search a snapshot instead of holding the graph
+	r.GET("/api/recipes/stream", streamRecipes(data.snapshot))
+	r.GET("/api/recipes/ws", watchSearch(data.snapshot))
+	r.Use(data.hold)
+	r.GET("/api/cache/stats", cacheStats(cache))
+
+	// /api/recipe and /api/recipes, see recipes.go. Their OpenAPI document is built from the same list
+	searches := &searcher{graph: graph, cache: cache}
+	if config.Index {
+		searches.index = loadIndex(graph, config.IndexFile)
+		data.onReload(func(next *search.RecipeGraph) func() {
+			index := loadIndex(next, config.IndexFile)
+			return func() { searches.index = index }
+		})
+	}
+	for _, e := range documentedEndpoints {
+		r.Handle(e.Method, e.Path, e.Handler(searches))
+	}
+	r.GET("/api/openapi.json", serveOpenAPI(openAPIDocument(documentedEndpoints)))
+
+	r.POST("/api/recipes/batch", searchBatch(searches))
+
+	// v2 answers every search with the same envelope, see v2.go
+	v2 := r.Group("/api/v2")
+	v2.GET("/recipe", searchRecipesV2(searches, true))
+	v2.GET("/recipes", searchRecipesV2(searches, false))
+	v2.POST("/recipes/batch", searchBatchV2(searches))
+	v2.GET("/path", findChainV2(graph))
+	r.NoRoute(func(c *gin.Context) {
+		if strings.HasPrefix(c.Request.URL.Path, "/api/v2/") {
+			routeNotFoundV2(c)
+			return
+		}
+		c.String(http.StatusNotFound, "404 page not found")
+	})
+
+	r.GET("/api/path", findChain(graph))
+	r.GET("/api/playthrough", planPlaythrough(graph))
+	r.POST("/api/playthrough", planPlaythrough(graph))
+	r.POST("/api/hints", suggestHints(graph))
+
+	analytics := search.Analyze(graph)
+	data.onReload(func(next *search.RecipeGraph) func() {
+		fresh := search.Analyze(next)
+		return func() { *analytics = *fresh }
+	})
+	r.GET("/api/elements/ranking", rankElements(analytics))
+	r.GET("/api/elements/:name/stats", elementStats(analytics))
+	r.GET("/api/graph/stats", graphStats(data))
+	r.GET("/api/graph/export", exportGraph(graph))
+
+	return r
+}
+
+// Most recipe trees one search may ask for, which bounds how long a single request can search
+const maxTrees = 1000
+
+// Error for a max outside 1..maxTrees
+func invalidMax() *apiError {
+	return newAPIError(http.StatusBadRequest, errInvalidParameter, "Max parameter must be between 1 and %d", maxTrees)
+}
+
+func writeSearchError(c *gin.Context, err error) {
+	c.JSON(searchErrorBody(err))
+}
+
+// Status and flat v1 error body for a failed search, shared by the single and batch endpoints
+func searchErrorBody(err error) (int, gin.H) {
+	apiErr := searchError(err)
+	body := gin.H{
+		"error":   true,
+		"type":    apiErr.Type,
+		"message": apiErr.Message,
+	}
+	if details, ok := apiErr.Details.(gin.H); ok {
+		for key, value := range details {
+			body[key] = value
+		}
+	}
+	return apiErr.status, body
+}
+
+// Compatibility flag for clients that still read the BFS/DFS specific result shapes
+func isLegacy(c *gin.Context) bool {
+	legacy, _ := strconv.ParseBool(c.DefaultQuery("legacy", "false"))
+	return legacy
+}
+
+// Constraints from the include= and exclude= queries.
+// Writes the error response and returns false when a name is unknown
+func parseConstraints(c *gin.Context, graph *search.RecipeGraph) (algorithm.Constraints, bool) {
+	constraints, err := constraintsFrom(c, graph)
+	if err != nil {
+		writeSearchError(c, err)
+		return constraints, false
+	}
+	return constraints, true
+}
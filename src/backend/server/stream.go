@@ -0,0 +1,102 @@
+package server
+
+import (
+	"backend/algorithm"
+	"backend/search"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// http://localhost:8080/api/recipes/stream?element=Acid%20Rain&algo=bfs|dfs&max=50[&legacy=true]
+// Server-Sent Events: one "tree" event per recipe tree as soon as it is found,
+// then a single "stats" event, or an "error" event if the search fails midway.
+// Not routed under dataset.hold, the stream searches the graph as it was when it started
+func streamRecipes(snapshot func() *search.RecipeGraph) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		graph := snapshot()
+		// Bad queries are answered like /api/recipes, nothing has been streamed yet
+		query, node, constraints, ok := bindRecipeQuery(c, graph)
+		if !ok {
+			return
+		}
+		element, algo, max := query.Element, query.Algo, query.Max
+		if max <= 0 || max > maxTrees {
+			writeSearchError(c, invalidMax())
+			return
+		}
+		if _, err := algorithm.Lookup(algo); err != nil {
+			writeSearchError(c, err)
+			return
+		}
+		if err := algorithm.CheckConstraints(node, graph, constraints); err != nil {
+			writeSearchError(c, err)
+			return
+		}
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+
+		// The search runs on its own goroutine and never waits for the client: bfs holds a
+		// lock every other bfs request needs, so a slow reader must only slow down its own stream.
+		// At most max trees are emitted, so the channel always has room for the next one
+		trees := make(chan *algorithm.RecipeTree, max)
+		type outcome struct {
+			result algorithm.SearchResult
+			err    error
+		}
+		finished := make(chan outcome, 1)
+		go func() {
+			defer close(trees)
+			result, err := algorithm.Run(algo, algorithm.SearchRequest{
+				Target:      node,
+				Graph:       graph,
+				MaxPaths:    max,
+				Constraints: constraints,
+				Emit: func(tree *algorithm.RecipeTree) bool {
+					trees <- tree
+					// Stop searching once the client has gone away
+					return c.Request.Context().Err() == nil
+				},
+			})
+			finished <- outcome{result, err}
+		}()
+
+		legacy := query.Legacy
+		index := 0
+		for tree := range trees {
+			var payload any = tree
+			if legacy {
+				payload = algorithm.LegacyPaths(algo, []*algorithm.RecipeTree{tree})[0]
+			}
+			c.SSEvent("tree", gin.H{
+				"index": index,
+				"tree":  payload,
+			})
+			c.Writer.Flush()
+			index++
+		}
+
+		done := <-finished
+		result, err := done.result, done.err
+		if err != nil {
+			// Same body as the JSON error of /api/recipes
+			_, body := searchErrorBody(err)
+			c.SSEvent("error", body)
+			c.Writer.Flush()
+			return
+		}
+
+		c.SSEvent("stats", gin.H{
+			"element":      element,
+			"algo":         result.Algo,
+			"count":        len(result.Trees),
+			"stats":        result.Stats,
+			"visitedNodes": result.Stats.NodesExpanded,
+		})
+		c.Writer.Flush()
+	}
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"backend/search"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// A client that stops reading: every write waits until release is closed
+type stalledWriter struct {
+	header  http.Header
+	writing chan struct{} // Closed by the first write
+	release chan struct{}
+	once    sync.Once
+	body    strings.Builder
+}
+
+func newStalledWriter() *stalledWriter {
+	return &stalledWriter{header: make(http.Header), writing: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (w *stalledWriter) Header() http.Header { return w.header }
+func (w *stalledWriter) WriteHeader(int)     {}
+func (w *stalledWriter) Flush()              {}
+func (w *stalledWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.writing) })
+	<-w.release
+	return w.body.Write(p)
+}
+
+func TestSlowStreamDoesNotBlockOtherSearches(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := New(testGraph(t), Config{CacheEntries: -1})
+
+	stalled := newStalledWriter()
+	streamed := make(chan struct{})
+	go func() {
+		defer close(streamed)
+		router.ServeHTTP(stalled, httptest.NewRequest(http.MethodGet, "/api/recipes/stream?element=Stone&algo=bfs&max=2", nil))
+	}()
+	select {
+	case <-stalled.writing:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the stream never wrote")
+	}
+
+	answered := make(chan int, 1)
+	go func() {
+		answered <- serve(t, router, "/api/recipe?element=Metal&algo=bfs").Code
+	}()
+	select {
+	case code := <-answered:
+		if code != http.StatusOK {
+			t.Errorf("bfs search answered %d", code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("a bfs search waited for the stalled stream")
+	}
+
+	close(stalled.release)
+	<-streamed
+	if body := stalled.body.String(); strings.Count(body, "event:tree") != 2 || !strings.Contains(body, "event:stats") {
+		t.Errorf("stream = %q, want two trees and the stats", body)
+	}
+}
+
+// A stream stuck on its client does not keep a reload, and every request after it, waiting
+func TestReloadDoesNotWaitForStreams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := New(testGraph(t), Config{Reload: func() (*search.RecipeGraph, error) { return testGraph(t), nil }})
+
+	stalled := newStalledWriter()
+	defer close(stalled.release)
+	go router.ServeHTTP(stalled, httptest.NewRequest(http.MethodGet, "/api/recipes/stream?element=Stone", nil))
+	select {
+	case <-stalled.writing:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the stream never wrote")
+	}
+
+	reloaded := make(chan int, 1)
+	go func() { reloaded <- postJSON(t, router, "/api/dataset/reload", "").Code }()
+	select {
+	case code := <-reloaded:
+		if code != http.StatusOK {
+			t.Errorf("reload answered %d", code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the reload waited for the stalled stream")
+	}
+}
+
+// Queries the stream turns down before it starts are answered like /api/recipes
+var badRecipeQueries = []string{
+	"algo=bfs",
+	"element=Unobtainium",
+	"element=Stone&max=0",
+	"element=Stone&algo=quantum",
+	"element=Stone&format=pdf",
+	"element=Metal&exclude=Stone",
+}
+
+func errorType(t *testing.T, recorder *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body struct{ Type string }
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("%q: %v", recorder.Body.String(), err)
+	}
+	return body.Type
+}
+
+func TestStreamErrorsMatchRecipeErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := New(testGraph(t), Config{CacheEntries: -1})
+
+	for _, query := range badRecipeQueries {
+		want := serve(t, router, "/api/recipes?"+query)
+		got := serve(t, router, "/api/recipes/stream?"+query)
+		if got.Code != want.Code || errorType(t, got) != errorType(t, want) {
+			t.Errorf("%s: stream answered %d %s, /api/recipes %d %s", query, got.Code, errorType(t, got), want.Code, errorType(t, want))
+		}
+	}
+
+	// Every Metal tree makes Stone from either Lava or Mud, so this only fails once the search runs
+	query := "element=Metal&include=Lava,Mud"
+	want := errorType(t, serve(t, router, "/api/recipes?"+query))
+	body := serve(t, router, "/api/recipes/stream?"+query).Body.String()
+	if !strings.Contains(body, "event:error") || !strings.Contains(body, `"type":"`+want+`"`) {
+		t.Errorf("%s: stream = %q, want an error event of type %s", query, body, want)
+	}
+}
@@ -0,0 +1,327 @@
+package server
+
+import (
+	"backend/algorithm"
+	"backend/search"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Error types clients can switch on. They are part of the API and must not change meaning,
+// v1 answers with the same names in its flat error body
+const (
+	errMissingParameter = "missing_parameter"
+	errInvalidParameter = "invalid_parameter"
+	errInvalidAlgorithm = "invalid_algorithm"
+	errElementNotFound  = "element_not_found"
+	errNoRecipe         = "no_recipe_found"
+	errUnreachable      = "unreachable_under_constraints"
+	errSearchLimit      = "search_limit_reached"
+	errSearchFailed     = "search_failed"
+	errRouteNotFound    = "route_not_found"
+)
+
+var errorTypes = []string{
+	errMissingParameter, errInvalidParameter, errInvalidAlgorithm, errElementNotFound,
+	errNoRecipe, errUnreachable, errSearchLimit, errSearchFailed, errRouteNotFound,
+}
+
+type apiError struct {
+	status  int
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"` // Extra data for some types, the blocking elements for unreachable_under_constraints
+}
+
+func (err *apiError) Error() string { return err.Message }
+
+func newAPIError(status int, errType, format string, args ...any) *apiError {
+	return &apiError{status: status, Type: errType, Message: fmt.Sprintf(format, args...)}
+}
+
+func elementNotFound(name string) *apiError {
+	return newAPIError(http.StatusNotFound, errElementNotFound, "Element '%s' not found", name)
+}
+
+// A search that ran but found no tree, v2 reports it as an error rather than an empty list
+func noRecipe(element string) error {
+	return fmt.Errorf("%w: %s cannot be crafted", algorithm.ErrNoRecipe, element)
+}
+
+// Maps the algorithm package errors to an API error
+func searchError(err error) *apiError {
+	var known *apiError
+	if errors.As(err, &known) {
+		return known
+	}
+	var unreachable *algorithm.ConstraintError
+	if errors.As(err, &unreachable) {
+		apiErr := newAPIError(http.StatusNotFound, errUnreachable, "%s", err.Error())
+		apiErr.Details = gin.H{"blocking": unreachable.Blocking}
+		return apiErr
+	}
+	if errors.Is(err, algorithm.ErrSearchLimit) {
+		return newAPIError(http.StatusUnprocessableEntity, errSearchLimit, "%s", err.Error())
+	}
+	if errors.Is(err, algorithm.ErrNoRecipe) {
+		return newAPIError(http.StatusNotFound, errNoRecipe, "%s", err.Error())
+	}
+	if errors.Is(err, algorithm.ErrInvalidRequest) {
+		return newAPIError(http.StatusBadRequest, errInvalidParameter, "%s", err.Error())
+	}
+	if errors.Is(err, algorithm.ErrUnknownAlgorithm) {
+		return newAPIError(http.StatusBadRequest, errInvalidAlgorithm, "Algorithm must be one of: %s", strings.Join(algorithm.Algorithms(), ", "))
+	}
+	return newAPIError(http.StatusInternalServerError, errSearchFailed, "%s", err.Error())
+}
+
+// Every v2 response has all four fields. Data is null on failure and error is null on success
+type envelope struct {
+	Data     any                    `json:"data"`
+	Stats    *algorithm.SearchStats `json:"stats"`
+	Warnings []string               `json:"warnings"`
+	Error    *apiError              `json:"error"`
+}
+
+func writeEnvelope(c *gin.Context, data any, stats *algorithm.SearchStats, warnings []string) {
+	if warnings == nil {
+		warnings = make([]string, 0)
+	}
+	c.JSON(http.StatusOK, envelope{Data: data, Stats: stats, Warnings: warnings})
+}
+
+func writeEnvelopeError(c *gin.Context, err error) {
+	apiErr := searchError(err)
+	c.JSON(apiErr.status, envelope{Warnings: make([]string, 0), Error: apiErr})
+}
+
+/* ----------------------------------------- Parameters ----------------------------------------------- */
+
+// The query parameters every v2 search understands, already checked against the graph
+type searchQuery struct {
+	Element     string
+	Algo        string
+	Max         int
+	Format      string
+	Target      *search.ElementNode
+	Constraints algorithm.Constraints
+}
+
+func parseSearchQuery(c *gin.Context, graph *search.RecipeGraph, defaultMax int) (searchQuery, error) {
+	query := searchQuery{
+		Element: c.Query("element"),
+		Algo:    strings.ToLower(c.DefaultQuery("algo", "bfs")),
+		Format:  strings.ToLower(c.DefaultQuery("format", "json")),
+	}
+	if query.Element == "" {
+		return query, newAPIError(http.StatusBadRequest, errMissingParameter, "Element parameter is required")
+	}
+	max, err := strconv.Atoi(c.DefaultQuery("max", strconv.Itoa(defaultMax)))
+	if err != nil || max <= 0 || max > maxTrees {
+		return query, invalidMax()
+	}
+	query.Max = max
+	if query.Format != "json" && !slices.Contains(algorithm.RenderFormats, query.Format) {
+		return query, newAPIError(http.StatusBadRequest, errInvalidParameter, "Format must be one of: json, %s", strings.Join(algorithm.RenderFormats, ", "))
+	}
+
+	if query.Target, err = search.GetElementByName(graph, query.Element); err != nil {
+		return query, elementNotFound(query.Element)
+	}
+	query.Constraints, err = constraintsFrom(c, graph)
+	return query, err
+}
+
+// Comma separated element names from include= and exclude=, both may also be repeated
+func constraintsFrom(c *gin.Context, graph *search.RecipeGraph) (algorithm.Constraints, error) {
+	var constraints algorithm.Constraints
+	lists := map[string]*[]*search.ElementNode{
+		"include": &constraints.Include,
+		"exclude": &constraints.Exclude,
+	}
+	for param, list := range lists {
+		for _, value := range c.QueryArray(param) {
+			for _, name := range strings.Split(value, ",") {
+				name = strings.TrimSpace(name)
+				if name == "" {
+					continue
+				}
+				node, err := search.GetElementByName(graph, name)
+				if err != nil {
+					return constraints, newAPIError(http.StatusNotFound, errElementNotFound, "Element '%s' in %s not found", name, param)
+				}
+				*list = append(*list, node)
+			}
+		}
+	}
+	return constraints, nil
+}
+
+// Things the caller asked for that v2 ignores or could not fully give
+func searchWarnings(c *gin.Context, query searchQuery, result algorithm.SearchResult) []string {
+	warnings := make([]string, 0)
+	if _, ok := c.GetQuery("legacy"); ok {
+		warnings = append(warnings, "legacy is not supported by v2 and was ignored, results are always recipe trees")
+	}
+	if len(result.Trees) < query.Max && !result.Stats.LimitHit {
+		warnings = append(warnings, fmt.Sprintf("Only %d of the %d requested recipe trees were found for %s", len(result.Trees), query.Max, result.Element))
+	}
+	return warnings
+}
+
+/* ----------------------------------------- Handlers ----------------------------------------------- */
+
+type recipesData struct {
+	Element string                  `json:"element"`
+	Algo    string                  `json:"algo"`
+	Trees   []*algorithm.RecipeTree `json:"trees"`
+}
+
+// http://localhost:8080/api/v2/recipes?element=Acid%20Rain&algo=bfs&max=5[&include=..][&exclude=..][&format=..]
+// /api/v2/recipe is the same search with max fixed to 1. Non-JSON formats answer with the drawing only
+func searchRecipesV2(s *searcher, single bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defaultMax := 5
+		if single {
+			defaultMax = 1
+		}
+		query, err := parseSearchQuery(c, s.graph, defaultMax)
+		if err != nil {
+			writeEnvelopeError(c, err)
+			return
+		}
+		warnings := make([]string, 0)
+		if single && query.Max != 1 {
+			warnings = append(warnings, "max is ignored by /api/v2/recipe, use /api/v2/recipes for more than one tree")
+			query.Max = 1
+		}
+
+		result, err := s.run(query.Algo, algorithm.SearchRequest{
+			Target:      query.Target,
+			Graph:       s.graph,
+			MaxPaths:    query.Max,
+			Constraints: query.Constraints,
+		})
+		if err == nil && len(result.Trees) == 0 {
+			err = noRecipe(result.Element)
+		}
+		if err != nil {
+			writeEnvelopeError(c, err)
+			return
+		}
+		if writeCacheHeaders(c, result) {
+			return
+		}
+		if query.Format != "json" {
+			writeRendered(c, query.Format, result.Trees)
+			return
+		}
+
+		writeEnvelope(c, recipesData{
+			Element: result.Element,
+			Algo:    result.Algo,
+			Trees:   result.Trees,
+		}, &result.Stats, append(warnings, searchWarnings(c, query, result.SearchResult)...))
+	}
+}
+
+type pathData struct {
+	From  string                `json:"from"`
+	To    string                `json:"to"`
+	Chain []string              `json:"chain"`
+	Tree  *algorithm.RecipeTree `json:"tree"`
+}
+
+// http://localhost:8080/api/v2/path?from=Fire&to=Acid%20Rain[&include=..][&exclude=..]
+func findChainV2(graph *search.RecipeGraph) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		from, to := c.Query("from"), c.Query("to")
+		if from == "" || to == "" {
+			writeEnvelopeError(c, newAPIError(http.StatusBadRequest, errMissingParameter, "From and to parameters are required"))
+			return
+		}
+		source, err := search.GetElementByName(graph, from)
+		if err != nil {
+			writeEnvelopeError(c, elementNotFound(from))
+			return
+		}
+		target, err := search.GetElementByName(graph, to)
+		if err != nil {
+			writeEnvelopeError(c, elementNotFound(to))
+			return
+		}
+		constraints, err := constraintsFrom(c, graph)
+		if err != nil {
+			writeEnvelopeError(c, err)
+			return
+		}
+
+		result, err := algorithm.Run("bidirectional", algorithm.SearchRequest{
+			Target:      target,
+			Source:      source,
+			Graph:       graph,
+			Constraints: constraints,
+		})
+		if err != nil {
+			writeEnvelopeError(c, err)
+			return
+		}
+		writeEnvelope(c, pathData{From: source.Name, To: target.Name, Chain: result.Chain, Tree: result.Trees[0]}, &result.Stats, nil)
+	}
+}
+
+type batchData struct {
+	Results   []envelope `json:"results"` // At the index of their request
+	Succeeded int        `json:"succeeded"`
+	Failed    int        `json:"failed"`
+}
+
+// POST http://localhost:8080/api/v2/recipes/batch, same body as /api/recipes/batch.
+// Every result is an envelope of its own, the outer one only fails when the body is unusable
+func searchBatchV2(s *searcher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		items, err := parseBatch(c)
+		if err != nil {
+			writeEnvelopeError(c, err)
+			return
+		}
+
+		data := batchData{Results: make([]envelope, len(items))}
+		ok := runBatch(c, len(items), func(i int) {
+			result, err := runBatchItem(s, items[i])
+			if err == nil && len(result.Trees) == 0 {
+				err = noRecipe(result.Element)
+			}
+			if err != nil {
+				data.Results[i] = envelope{Warnings: make([]string, 0), Error: searchError(err)}
+				return
+			}
+			data.Results[i] = envelope{
+				Data:     recipesData{Element: result.Element, Algo: result.Algo, Trees: result.Trees},
+				Stats:    &result.Stats,
+				Warnings: make([]string, 0),
+			}
+		})
+		if !ok {
+			return
+		}
+		for _, result := range data.Results {
+			if result.Error != nil {
+				data.Failed++
+			}
+		}
+		data.Succeeded = len(items) - data.Failed
+		writeEnvelope(c, data, nil, nil)
+	}
+}
+
+// Unknown /api/v2 routes still answer with an envelope
+func routeNotFoundV2(c *gin.Context) {
+	writeEnvelopeError(c, newAPIError(http.StatusNotFound, errRouteNotFound, "No route %s %s", c.Request.Method, c.Request.URL.Path))
+}
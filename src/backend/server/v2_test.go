@@ -0,0 +1,116 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The test graph plus Glass, whose only recipe needs a higher tier ingredient
+func v2Router(t *testing.T) http.Handler {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	recipes := testRecipes()
+	recipes.Element = append(recipes.Element, "Glass")
+	recipes.Recipe["Glass"] = [][]string{{"Metal", "Fire"}}
+	recipes.Tiering["Glass"] = 1
+	return New(graphOf(t, recipes), Config{})
+}
+
+// Every field decoded, so a missing one is told apart from a null one
+type testEnvelope struct {
+	Data     json.RawMessage `json:"data"`
+	Stats    json.RawMessage `json:"stats"`
+	Warnings []string        `json:"warnings"`
+	Error    *apiError       `json:"error"`
+}
+
+func envelopeOf(t *testing.T, router http.Handler, url string) (int, testEnvelope) {
+	t.Helper()
+	recorder := serve(t, router, url)
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(recorder.Body.Bytes(), &fields); err != nil {
+		t.Fatalf("%s: %v: %s", url, err, recorder.Body)
+	}
+	for _, field := range []string{"data", "stats", "warnings", "error"} {
+		if _, ok := fields[field]; !ok {
+			t.Errorf("%s: the envelope has no %s field", url, field)
+		}
+	}
+	var body testEnvelope
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Warnings == nil {
+		t.Errorf("%s: warnings is null, want a list", url)
+	}
+	return recorder.Code, body
+}
+
+func TestV2Success(t *testing.T) {
+	router := v2Router(t)
+
+	tests := []struct {
+		url      string
+		trees    int
+		warnings int
+	}{
+		{"/api/v2/recipe?element=Metal", 1, 0},
+		{"/api/v2/recipes?element=Stone&max=2&algo=dfs", 2, 0},
+		// Stone has two trees only
+		{"/api/v2/recipes?element=Stone&max=5", 2, 1},
+		{"/api/v2/recipe?element=Metal&max=3&legacy=true", 1, 2},
+	}
+	for _, test := range tests {
+		status, body := envelopeOf(t, router, test.url)
+		if status != http.StatusOK || body.Error != nil {
+			t.Errorf("%s: got %d and error %+v, want 200", test.url, status, body.Error)
+			continue
+		}
+		var data recipesData
+		if err := json.Unmarshal(body.Data, &data); err != nil {
+			t.Fatal(err)
+		}
+		if len(data.Trees) != test.trees || len(body.Warnings) != test.warnings || string(body.Stats) == "null" {
+			t.Errorf("%s: %d trees, warnings %q and stats %s, want %d trees and %d warnings",
+				test.url, len(data.Trees), body.Warnings, body.Stats, test.trees, test.warnings)
+		}
+	}
+}
+
+func TestV2Errors(t *testing.T) {
+	router := v2Router(t)
+
+	tests := []struct {
+		url     string
+		status  int
+		errType string
+	}{
+		{"/api/v2/recipes", http.StatusBadRequest, errMissingParameter},
+		{"/api/v2/recipes?element=Stone&max=0", http.StatusBadRequest, errInvalidParameter},
+		{"/api/v2/recipes?element=Stone&max=1001", http.StatusBadRequest, errInvalidParameter},
+		{"/api/v2/recipes?element=Stone&format=pdf", http.StatusBadRequest, errInvalidParameter},
+		{"/api/v2/recipes?element=Stone&algo=quantum", http.StatusBadRequest, errInvalidAlgorithm},
+		{"/api/v2/recipe?element=Gold", http.StatusNotFound, errElementNotFound},
+		{"/api/v2/recipes?element=Stone&include=Gold", http.StatusNotFound, errElementNotFound},
+		{"/api/v2/recipes?element=Metal&exclude=Lava,Mud", http.StatusNotFound, errUnreachable},
+		// Found in the graph, but without a single recipe tree
+		{"/api/v2/recipe?element=Glass", http.StatusNotFound, errNoRecipe},
+		{"/api/v2/recipes?element=Glass&algo=dfs", http.StatusNotFound, errNoRecipe},
+		{"/api/v2/path?from=Glass&to=Metal", http.StatusNotFound, errNoRecipe},
+		{"/api/v2/path?from=Fire", http.StatusBadRequest, errMissingParameter},
+		{"/api/v2/nothing", http.StatusNotFound, errRouteNotFound},
+	}
+	for _, test := range tests {
+		status, body := envelopeOf(t, router, test.url)
+		if status != test.status || body.Error == nil || body.Error.Type != test.errType {
+			t.Errorf("%s: got %d and error %+v, want %d %s", test.url, status, body.Error, test.status, test.errType)
+			continue
+		}
+		if string(body.Data) != "null" || string(body.Stats) != "null" || body.Error.Message == "" {
+			t.Errorf("%s: data %s, stats %s and message %q, want null, null and a message", test.url, body.Data, body.Stats, body.Error.Message)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"backend/algorithm"
+	"backend/search"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// Events a peer may fall behind before further ones are dropped, and how long one write may take
+const (
+	wsQueueSize    = 1024
+	wsWriteTimeout = 10 * time.Second
+)
+
+var upgrader = websocket.Upgrader{
+	// Same policy as the CORS middleware, any origin is allowed during development
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// ws://localhost:8080/api/recipes/ws?element=Acid%20Rain&algo=bfs|dfs&max=5
+// Streams every search event ({"seq", "event": SearchEvent}) while the search runs,
+// then one {"seq", "done": true, "stats", "dropped"} message, and closes the connection.
+// dropped counts the events left out because the peer read them too slowly.
+// Not routed under dataset.hold, the search runs over the graph as it was when it started
+func watchSearch(snapshot func() *search.RecipeGraph) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		graph := snapshot()
+		// Bad queries are answered like /api/recipes, before the upgrade
+		query, node, constraints, ok := bindRecipeQuery(c, graph)
+		if !ok {
+			return
+		}
+		algo, max := query.Algo, query.Max
+		if c.Query("max") == "" {
+			max = 1 // Following one search is what the event view is for
+		}
+		if max <= 0 || max > maxTrees {
+			writeSearchError(c, invalidMax())
+			return
+		}
+		if _, err := algorithm.Lookup(algo); err != nil {
+			writeSearchError(c, err)
+			return
+		}
+		if err := algorithm.CheckConstraints(node, graph, constraints); err != nil {
+			writeSearchError(c, err)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			// The upgrader already answered the client
+			log.Println("websocket upgrade failed:", err)
+			return
+		}
+		defer conn.Close()
+
+		// The search never waits for the peer: bfs holds a lock every other bfs request needs.
+		// Events go through a bounded queue written from this goroutine, and events that do not
+		// fit are dropped and counted. DFS goroutines may still report events after the search
+		// returned, those are ignored once the queue is closed
+		queue := make(chan gin.H, wsQueueSize)
+		var mu sync.Mutex
+		closed := false
+		dropped := 0
+		var disconnected atomic.Bool
+		enqueue := func(message gin.H) {
+			mu.Lock()
+			defer mu.Unlock()
+			if closed {
+				return
+			}
+			select {
+			case queue <- message:
+			default:
+				dropped++
+			}
+		}
+		// The last message waits for room, the search is over by then
+		finish := func(message gin.H) {
+			mu.Lock()
+			defer mu.Unlock()
+			if _, done := message["done"]; done {
+				message["dropped"] = dropped
+			}
+			queue <- message
+			closed = true
+			close(queue)
+		}
+
+		go func() {
+			result, err := algorithm.Run(algo, algorithm.SearchRequest{
+				Target:      node,
+				Graph:       graph,
+				MaxPaths:    max,
+				Constraints: constraints,
+				Observer: algorithm.ObserverFunc(func(event algorithm.SearchEvent) {
+					enqueue(gin.H{"event": event})
+				}),
+				Emit: func(tree *algorithm.RecipeTree) bool {
+					return !disconnected.Load()
+				},
+			})
+			if err != nil {
+				// Same body as the JSON error of /api/recipes
+				_, body := searchErrorBody(err)
+				finish(body)
+				return
+			}
+			finish(gin.H{
+				"done":  true,
+				"algo":  result.Algo,
+				"count": len(result.Trees),
+				"stats": result.Stats,
+			})
+		}()
+
+		seq := 0
+		for message := range queue {
+			// Keep draining after a failed write so the search can finish
+			if disconnected.Load() {
+				continue
+			}
+			message["seq"] = seq
+			seq++
+			conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := conn.WriteJSON(message); err != nil {
+				disconnected.Store(true)
+			}
+		}
+		if disconnected.Load() {
+			return
+		}
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	}
+}
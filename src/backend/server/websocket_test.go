@@ -0,0 +1,79 @@
+package server
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+func TestWatchSearchSendsEventsThenDone(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := httptest.NewServer(New(testGraph(t), Config{}))
+	defer server.Close()
+
+	for _, algo := range []string{"bfs", "dfs"} {
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/recipes/ws?element=Stone&max=2&algo="+algo, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var messages []map[string]any
+		for {
+			var message map[string]any
+			if err := conn.ReadJSON(&message); err != nil {
+				if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+					t.Errorf("%s: connection ended with %v", algo, err)
+				}
+				break
+			}
+			messages = append(messages, message)
+		}
+		conn.Close()
+
+		for i, message := range messages {
+			if message["seq"] != float64(i) {
+				t.Errorf("%s: message %d has seq %v", algo, i, message["seq"])
+			}
+		}
+		last := messages[len(messages)-1]
+		if len(messages) < 2 || last["done"] != true || last["count"] != float64(2) || last["dropped"] != float64(0) {
+			t.Errorf("%s: last of %d messages = %v, want done with 2 trees and nothing dropped", algo, len(messages), last)
+		}
+	}
+}
+
+func TestWatchSearchErrorsMatchRecipeErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := New(testGraph(t), Config{CacheEntries: -1})
+
+	for _, query := range badRecipeQueries {
+		want := serve(t, router, "/api/recipes?"+query)
+		got := serve(t, router, "/api/recipes/ws?"+query)
+		if got.Code != want.Code || errorType(t, got) != errorType(t, want) {
+			t.Errorf("%s: ws answered %d %s, /api/recipes %d %s", query, got.Code, errorType(t, got), want.Code, errorType(t, want))
+		}
+	}
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+	query := "element=Metal&include=Lava,Mud"
+	want := errorType(t, serve(t, router, "/api/recipes?"+query))
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/recipes/ws?"+query, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var last map[string]any
+	for {
+		var message map[string]any
+		if err := conn.ReadJSON(&message); err != nil {
+			break
+		}
+		last = message
+	}
+	if last["error"] != true || last["type"] != want {
+		t.Errorf("%s: last message = %v, want an error of type %s", query, last, want)
+	}
+}
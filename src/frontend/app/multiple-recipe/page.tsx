@@ -37,7 +37,7 @@ const handleSearch = async () => {
   
 
   try {
-      const response = await fetch(`${config.API_URL}/api/recipes?element=${encodeURIComponent(searchQuery.trim())}&algo=${algo}&max=${selectedNumR}`);
+      const response = await fetch(`${config.API_URL}/api/recipes?element=${encodeURIComponent(searchQuery.trim())}&algo=${algo}&max=${selectedNumR}&legacy=true`);
       const data = await response.json();
       
       if (data.error) {
@@ -82,7 +82,7 @@ function ResultContent() {
         setIsLoading(true);
         console.log("Fetching recipe data...");
         const t0 = performance.now();
-        const url = `${config.API_URL}/api/recipes?element=${encodeURIComponent(element)}&algo=${algo}&max=${max}`;
+        const url = `${config.API_URL}/api/recipes?element=${encodeURIComponent(element)}&algo=${algo}&max=${max}&legacy=true`;
         console.log("Fetching from URL:", url);
         
         const res = await fetch(url);
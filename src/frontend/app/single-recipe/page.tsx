@@ -34,7 +34,7 @@ const SingleRecipePage = () => {
     console.log(`Searching for: ${searchQuery} using ${selectedAlgo === 1 ? 'BFS' : 'DFS'}`);
     const algo = selectedAlgo === 1 ? 'BFS' : 'DFS';
     try {
-      const response = await fetch(`${config.API_URL}/api/recipe?element=${encodeURIComponent(searchQuery.trim())}&algo=${algo}`);
+      const response = await fetch(`${config.API_URL}/api/recipe?element=${encodeURIComponent(searchQuery.trim())}&algo=${algo}&legacy=true`);
       const data = await response.json();
       console.log(data);
 
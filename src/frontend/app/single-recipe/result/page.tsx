@@ -63,7 +63,7 @@ function ResultContent() {
         setIsLoading(true);
         const t0 = performance.now();
         const res = await fetch(
-          `${config.API_URL}/api/recipe?element=${encodeURIComponent(element)}&algo=${algo}`
+          `${config.API_URL}/api/recipe?element=${encodeURIComponent(element)}&algo=${algo}&legacy=true`
         );
         const json = await res.json() as ApiResponse;
         console.log("API Response:", json);